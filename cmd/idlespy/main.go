@@ -10,26 +10,45 @@ import (
 )
 
 const chartDescriptions = `
+Commands:
+  tree				 - Renders the goroutine spawn tree with each subtree's lifetime, blocked time and efficiency
+
 Available chart types:
-  score				 - Shows the efficiency score for each goroutine, ratio of the lifetime of the goroutine and the time it was blocked
+  score				 - Shows each goroutine's lifetime split into working, blocked and unaccounted time
   sum-total-blocked-time - Displays the sum of the total blocked time for each select across all goroutines
   avg-blocked-time   - Shows the average blocked time across all goroutines and selects
   p90-blocked-time   - Displays the 90th percentile blocked time for each select across all goroutines
   p99-blocked-time   - Shows the 99th percentile blocked time for each select across all goroutines
   hits				 - Visualizes the total number of hits for each select across all goroutines
+  timeline			 - Shows how hits, blocked time and efficiency evolved, read from the snapshot stream
+  sites				 - Shows how often each case of a select site won and how fair the site is
+  occupancy			 - Shows how full each sampled channel buffer was over the run
+  categories			 - Splits each group's blocked time into input-starved, output-backpressured, timer-idle and cancellation
+  shutdown			 - Shows how long each group's goroutines took to exit after their context was cancelled
+  exits				 - Summarizes how each group's goroutines ended: returned, cancelled, channel closed or panicked
 `
 
 func main() {
 	chartType := flag.String("chart", "score", "Type of chart to generate (see descriptions below)")
+	flag.Float64Var(&visualization.OverheadThreshold, "overhead-threshold", visualization.OverheadThreshold,
+		"Fraction of goroutine lifetime spent in the tracker above which a warning is printed")
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage of %s [tree]:\n", os.Args[0])
 		flag.PrintDefaults()
 		fmt.Fprint(os.Stderr, chartDescriptions)
 	}
 
 	flag.Parse()
 
+	if flag.Arg(0) == "tree" {
+		if err := visualization.GenerateTree(); err != nil {
+			fmt.Printf("Error generating tree: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var err error
 	switch *chartType {
 	case "score":
@@ -44,6 +63,18 @@ func main() {
 		err = visualization.GenerateBarChart(sharedtypes.Percentile99)
 	case "hits":
 		err = visualization.GenerateBarChart(sharedtypes.TotalHits)
+	case "timeline":
+		err = visualization.GenerateTimeline()
+	case "sites":
+		err = visualization.GenerateSiteChart()
+	case "occupancy":
+		err = visualization.GenerateOccupancyChart()
+	case "categories":
+		err = visualization.GenerateCategoryChart()
+	case "shutdown":
+		err = visualization.GenerateShutdownChart()
+	case "exits":
+		err = visualization.GenerateExitChart()
 	default:
 		fmt.Printf("Error: unknown chart type '%s'\n", *chartType)
 		fmt.Print(chartDescriptions)
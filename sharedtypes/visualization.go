@@ -28,10 +28,26 @@ func (vt VisualizationType) String() string {
 }
 
 type CaseJSON struct {
-	CaseName         string `json:"case_name"`
+	CaseName string `json:"case_name"`
+	// case this one is nested in, empty for top-level cases
+	Parent           string `json:"parent"`
 	Hits             int64  `json:"hits"`
 	TotalBlockedTime int64  `json:"total_blocked_time"`
-	AvgBlockedTime   int64  `json:"average_blocked_time"`
-	Percentile90     int64  `json:"percentile_90"`
-	Percentile99     int64  `json:"percentile_99"`
+	// zero when the handler time was not recorded
+	TotalHandlingTime int64 `json:"total_handling_time"`
+	AvgBlockedTime    int64 `json:"average_blocked_time"`
+	Percentile90      int64 `json:"percentile_90"`
+	Percentile99      int64 `json:"percentile_99"`
+	// location that recorded the case, nil unless call sites were captured
+	CallSite *CallSite `json:"call_site"`
+	// zero when every hit was measured
+	SampledHits int64   `json:"sampled_hits"`
+	SampleRate  float64 `json:"sample_rate"`
+}
+
+// CallSite is the location of the code that recorded a select case
+type CallSite struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
 }
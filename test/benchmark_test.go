@@ -0,0 +1,80 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+func BenchmarkTrackSelectCase(b *testing.B) {
+	gm := tracker.NewGoroutineManager()
+	gm.Enabled = true
+	id := gm.TrackGoroutineStart()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		gm.TrackSelectCase("case1", time.Microsecond, id)
+	}
+}
+
+func BenchmarkTrackSelectCaseCallSites(b *testing.B) {
+	gm := tracker.NewGoroutineManager()
+	gm.Enabled = true
+	gm.CaptureCallSites = true
+	id := gm.TrackGoroutineStart()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		gm.TrackSelectCase("case1", time.Microsecond, id)
+	}
+}
+
+func BenchmarkTrackSelectCaseDisabled(b *testing.B) {
+	gm := tracker.NewGoroutineManager()
+	gm.Enabled = false
+	id := gm.TrackGoroutineStart()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		gm.TrackSelectCase("case1", time.Microsecond, id)
+	}
+}
+
+func BenchmarkTrackGoroutineDisabled(b *testing.B) {
+	gm := tracker.NewGoroutineManager()
+	gm.Enabled = false
+
+	b.ReportAllocs()
+	for b.Loop() {
+		id := gm.TrackGoroutineStart()
+		gm.TrackGoroutineEnd(id)
+	}
+}
+
+func TestDisabledTrackingDoesNotAllocate(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.Enabled = false
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		id := gm.TrackGoroutineStart()
+		gm.TrackSelectCase("case1", time.Microsecond, id)
+		gm.TrackGoroutineEnd(id)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected disabled tracking not to allocate, got %v allocations per run", allocs)
+	}
+}
+
+func BenchmarkTrackCase(b *testing.B) {
+	gm := tracker.NewGoroutineManager()
+	gm.Enabled = true
+	gm.MaxLatencySamples = 1024
+	handle := gm.RegisterCase("case1")
+	id := gm.TrackGoroutineStart()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		gm.TrackCase(handle, time.Microsecond, id)
+	}
+}
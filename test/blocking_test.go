@@ -0,0 +1,169 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+func TestWaitGroupAndCondBlocking(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	workers := &tracker.WaitGroup{Name: "workers", Manager: gm}
+	var mu sync.Mutex
+	ready := tracker.NewCond(gm, "ready", &mu)
+
+	id := gm.TrackGoroutineStart()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		time.Sleep(3 * time.Millisecond)
+	}()
+	workers.Wait()
+
+	signaled := false
+	go func() {
+		time.Sleep(2 * time.Millisecond)
+		mu.Lock()
+		signaled = true
+		mu.Unlock()
+		ready.Signal()
+	}()
+	mu.Lock()
+	for !signaled {
+		ready.Wait()
+	}
+	mu.Unlock()
+
+	stats := gm.GetGoroutineStats(id)
+	wg := stats.GetSelectCaseStats("workers" + tracker.WaitCaseSuffix)
+	if wg == nil || wg.GetCaseHits() != 1 || wg.GetCaseTime() < 3*time.Millisecond {
+		t.Fatalf("Expected one WaitGroup wait of at least 3ms, got %+v", wg)
+	}
+	cond := stats.GetSelectCaseStats("ready" + tracker.WaitCaseSuffix)
+	if cond == nil || cond.GetCaseTime() < time.Millisecond {
+		t.Fatalf("Expected the Cond wait to be recorded, got %+v", cond)
+	}
+
+	if stats.GetTotalSelectBlockedTime() != wg.GetCaseTime()+cond.GetCaseTime() {
+		t.Errorf("Expected the blocked time to be the waits, got %v", stats.GetTotalSelectBlockedTime())
+	}
+	if categories := stats.GetBlockedCategories(); categories.GetTotal() != stats.GetTotalSelectBlockedTime() {
+		t.Errorf("Expected the categories to cover the waits, got %+v", categories)
+	}
+
+	goroutineJSON := gm.Snapshot().JSON("test").Goroutines[jsonKey(id)]
+	if goroutineJSON.SelectCaseStats["workers"+tracker.WaitCaseSuffix].Hits != 1 {
+		t.Errorf("Expected the waits in the JSON report, got %+v", goroutineJSON)
+	}
+}
+
+func TestSemaphoreBlocking(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	sem := tracker.NewSemaphore(gm, "connections", 2)
+	id := gm.TrackGoroutineStart()
+
+	if err := sem.Acquire(context.Background(), 2); err != nil {
+		t.Fatalf("Error acquiring: %v", err)
+	}
+	if sem.TryAcquire(1) {
+		t.Fatal("Expected TryAcquire to fail while the semaphore is full")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Millisecond)
+	defer cancel()
+	if err := sem.Acquire(ctx, 1); err != context.DeadlineExceeded {
+		t.Fatalf("Expected the acquisition to time out, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(2 * time.Millisecond)
+		sem.Release(2)
+	}()
+	if err := sem.Acquire(context.Background(), 2); err != nil {
+		t.Fatalf("Error acquiring: %v", err)
+	}
+	sem.Release(2)
+
+	acquire := gm.GetGoroutineStats(id).GetSelectCaseStats("connections" + tracker.AcquireCaseSuffix)
+	if acquire == nil || acquire.GetCaseHits() != 3 {
+		t.Fatalf("Expected 3 semaphore waits, got %+v", acquire)
+	}
+	if acquire.GetCaseTime() < 4*time.Millisecond {
+		t.Errorf("Expected at least 4ms of waiting, got %v", acquire.GetCaseTime())
+	}
+}
+
+func TestSemaphoreFIFO(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	sem := tracker.NewSemaphore(gm, "fifo", 3)
+	ctx := context.Background()
+
+	sem.Acquire(ctx, 3)
+	large := make(chan struct{})
+	go func() {
+		sem.Acquire(ctx, 3)
+		close(large)
+	}()
+	time.Sleep(2 * time.Millisecond)
+
+	// a small acquisition arriving later must not overtake the large one
+	sem.Release(1)
+	if sem.TryAcquire(1) {
+		t.Fatal("Expected TryAcquire to wait behind the queued acquisition")
+	}
+	sem.Release(2)
+	<-large
+	sem.Release(3)
+}
+
+func TestBlockingRolledUp(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.MaxFinishedGoroutines = 1
+	sem := tracker.NewSemaphore(gm, "pool", 1)
+
+	for range 3 {
+		runTracked(gm, "handlers", func(id tracker.GoroutineId) {
+			sem.Acquire(context.Background(), 1)
+			sem.Release(1)
+		})
+	}
+
+	group := gm.GetAllGroupStats()["handlers"]
+	if group == nil || group.SelectStats["pool"+tracker.AcquireCaseSuffix] == nil {
+		t.Fatalf("Expected the rolled up semaphore waits, got %+v", group)
+	}
+	if hits := group.SelectStats["pool"+tracker.AcquireCaseSuffix].GetCaseHits(); hits != 2 {
+		t.Errorf("Expected 2 rolled up semaphore waits, got %d", hits)
+	}
+}
+
+func TestSyncPrimitivesWithoutManager(t *testing.T) {
+	var mu sync.Mutex
+	cond := tracker.NewCond(nil, "ready", &mu)
+	sem := tracker.NewSemaphore(nil, "connections", 1)
+	var wg tracker.WaitGroup
+
+	if err := sem.Acquire(context.Background(), 1); err != nil {
+		t.Fatalf("Error acquiring: %v", err)
+	}
+	sem.Release(1)
+
+	// the signal can only be sent once Wait released mu
+	mu.Lock()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mu.Lock()
+		cond.Signal()
+		mu.Unlock()
+	}()
+	cond.Wait()
+	mu.Unlock()
+	wg.Wait()
+}
@@ -0,0 +1,68 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+func TestCaptureCallSites(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.CaptureCallSites = true
+
+	var line int
+	id := runTracked(gm, "", func(id tracker.GoroutineId) {
+		_, _, line, _ = runtime.Caller(0)
+		gm.TrackSelectCase("received", time.Millisecond, id)
+		gm.TrackSelectCaseHandled("handled", time.Millisecond, time.Millisecond, id)
+		gm.TrackNestedSelectCase("received", "sent", time.Millisecond, id)
+	})
+
+	stats := gm.GetGoroutineStats(id)
+	for i, caseName := range []string{"received", "handled", "received" + tracker.NestedCaseSeparator + "sent"} {
+		checkCase(t, stats, caseName, 1, time.Millisecond, time.Millisecond)
+		at := stats.GetSelectCaseStats(caseName).CallSite
+		if at == nil {
+			t.Errorf("Expected a call site for %s", caseName)
+			continue
+		}
+		if !strings.HasSuffix(at.File, "callsite_test.go") || at.Line != line+1+i {
+			t.Errorf("Expected %s to be recorded at callsite_test.go:%d, got %s", caseName, line+1+i, at)
+		}
+		if !strings.Contains(at.Function, "TestCaptureCallSites") {
+			t.Errorf("Expected the recording function for %s, got %q", caseName, at.Function)
+		}
+	}
+
+	caseJSON := gm.Snapshot().JSON("test").Goroutines[jsonKey(id)].SelectCaseStats["received"]
+	if caseJSON.CallSite == nil || caseJSON.CallSite.Line != line+1 {
+		t.Errorf("Expected the call site in the JSON report, got %+v", caseJSON.CallSite)
+	}
+}
+
+func TestEmptyCaseNameDefaultsToCallSite(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+
+	var line int
+	id := runTracked(gm, "", func(id tracker.GoroutineId) {
+		_, _, line, _ = runtime.Caller(0)
+		gm.TrackSelectCase("", time.Millisecond, id)
+		gm.TrackSelectCase("named", time.Millisecond, id)
+	})
+
+	stats := gm.GetGoroutineStats(id)
+	if len(stats.GetSelectStats()) != 2 {
+		t.Errorf("Expected the unnamed and the named case, got %v", stats.GetSelectStats())
+	}
+	checkCase(t, stats, fmt.Sprintf("callsite_test.go:%d", line+1), 1, time.Millisecond, time.Millisecond)
+	checkCase(t, stats, "named", 1, time.Millisecond, time.Millisecond)
+	if stats.GetSelectCaseStats("named").CallSite != nil {
+		t.Error("Expected named cases not to capture call sites by default")
+	}
+}
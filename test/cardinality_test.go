@@ -0,0 +1,83 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+func TestMaxCaseNames(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.MaxCaseNames = 2
+
+	var caseNames []string
+	for i := range 5 {
+		caseNames = append(caseNames, fmt.Sprintf("request-%d", i))
+	}
+	id := trackCases(gm, append(caseNames, "request-0")...)
+
+	stats := gm.GetGoroutineStats(id)
+	if len(stats.SelectStats) != 3 {
+		t.Errorf("Expected 2 cases plus %s, got %d", tracker.OtherCaseName, len(stats.SelectStats))
+	}
+	checkCase(t, stats, "request-0", 2, 2*time.Millisecond, time.Millisecond)
+	checkCase(t, stats, "request-1", 1, time.Millisecond, time.Millisecond)
+	checkCase(t, stats, tracker.OtherCaseName, 3, 3*time.Millisecond, time.Millisecond)
+
+	// the limit is shared by all goroutines
+	other := gm.GetGoroutineStats(trackCases(gm, "request-4"))
+	if len(other.SelectStats) != 1 {
+		t.Errorf("Expected the global limit to apply to every goroutine, got %v", other.SelectStats)
+	}
+	checkCase(t, other, tracker.OtherCaseName, 1, time.Millisecond, time.Millisecond)
+
+	snap := gm.Snapshot()
+	if snap.DroppedCaseNames != 3 {
+		t.Errorf("Expected 3 dropped case names, got %d", snap.DroppedCaseNames)
+	}
+	if snap.JSON("test").DroppedCaseNames != 3 {
+		t.Error("Expected dropped case names in the JSON report")
+	}
+}
+
+func TestMaxCaseNamesPerGoroutine(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.MaxCaseNamesPerGoroutine = 1
+
+	first := gm.GetGoroutineStats(trackCases(gm, "a", "b", "b"))
+	second := gm.GetGoroutineStats(trackCases(gm, "b"))
+
+	checkCase(t, first, "a", 1, time.Millisecond, time.Millisecond)
+	checkCase(t, first, tracker.OtherCaseName, 2, 2*time.Millisecond, time.Millisecond)
+	if len(second.SelectStats) != 1 {
+		t.Errorf("Expected the per-goroutine limit not to affect other goroutines, got %v", second.SelectStats)
+	}
+	checkCase(t, second, "b", 1, time.Millisecond, time.Millisecond)
+
+	if dropped := gm.Snapshot().DroppedCaseNames; dropped != 1 {
+		t.Errorf("Expected 1 dropped case name, got %d", dropped)
+	}
+}
+
+func TestMaxCaseNamesBoundsKinds(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.MaxCaseNames = 2
+
+	for i := range 3 {
+		gm.SetCaseKind(fmt.Sprintf("request-%d", i), tracker.KindReceive)
+	}
+
+	stats := gm.GetGoroutineStats(trackCases(gm, "request-0", "request-2"))
+	checkCase(t, stats, "request-0", 1, time.Millisecond, time.Millisecond)
+	checkCase(t, stats, "request-2", 1, time.Millisecond, time.Millisecond)
+	if kind := stats.GetSelectCaseStats("request-0").Kind; kind != tracker.KindReceive {
+		t.Errorf("Expected kind %q for a name within the limit, got %q", tracker.KindReceive, kind)
+	}
+	if kind := stats.GetSelectCaseStats("request-2").Kind; kind != "" {
+		t.Errorf("Expected the kind past the limit to be ignored, got %q", kind)
+	}
+}
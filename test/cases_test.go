@@ -0,0 +1,94 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+func TestRegisteredCases(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	received := gm.RegisterCase("received")
+	cancelled := gm.RegisterCase("cancelled")
+	if gm.RegisterCase("received") != received {
+		t.Error("Registering a case twice returned a different handle")
+	}
+
+	id := gm.TrackGoroutineStart()
+	gm.TrackCase(received, 10*time.Millisecond, id)
+	gm.TrackCase(received, 20*time.Millisecond, id)
+	gm.TrackSelectCase("cancelled", 5*time.Millisecond, id)
+
+	stats := gm.GetGoroutineStats(id)
+	if hits := stats.GetSelectCaseStats("received").GetCaseHits(); hits != 2 {
+		t.Errorf("Expected 2 hits through the handle, got %d", hits)
+	}
+
+	// recording by name and by handle share the same slot
+	gm.TrackCase(cancelled, 5*time.Millisecond, id)
+	if hits := stats.GetSelectCaseStats("cancelled").GetCaseHits(); hits != 2 {
+		t.Errorf("Expected 2 hits for cancelled, got %d", hits)
+	}
+
+	// unregistered names are still recorded unless strict
+	gm.TrackSelectCase("unknown", time.Millisecond, id)
+	if stats.GetSelectCaseStats("unknown") == nil {
+		t.Error("Expected unregistered case to be recorded with a warning")
+	}
+
+	gm.StrictCases = true
+	gm.TrackSelectCase("rejected", time.Millisecond, id)
+	if stats.GetSelectCaseStats("rejected") != nil {
+		t.Error("Expected unregistered case to be rejected in strict mode")
+	}
+}
+
+func TestStrictCasesKeepNestedAndSiteHitsOfRegisteredCases(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.StrictCases = true
+	gm.RegisterCase("data")
+	id := gm.TrackGoroutineStart()
+
+	gm.TrackSiteCase("producer", "data", 2*time.Millisecond, id)
+	gm.TrackNestedSelectCase("data", "data", 3*time.Millisecond, id)
+	gm.TrackSiteCase("producer", "unknown", time.Millisecond, id)
+
+	stats := gm.GetGoroutineStats(id)
+	site := stats.GetSelectCaseStats("producer" + tracker.SiteCaseSeparator + "data")
+	if site.GetCaseHits() != 1 || site.GetCaseTime() != 2*time.Millisecond {
+		t.Errorf("Expected 1 site hit of 2ms for the registered case, got %d hits of %v", site.GetCaseHits(), site.GetCaseTime())
+	}
+	nested := stats.GetSelectCaseStats("data" + tracker.NestedCaseSeparator + "data")
+	if nested.GetCaseHits() != 1 || nested.GetCaseTime() != 3*time.Millisecond {
+		t.Errorf("Expected 1 nested hit of 3ms for the registered case, got %d hits of %v", nested.GetCaseHits(), nested.GetCaseTime())
+	}
+	if stats.GetSelectCaseStats("producer"+tracker.SiteCaseSeparator+"unknown") != nil {
+		t.Error("Expected site hits of an unregistered case to be rejected in strict mode")
+	}
+}
+
+func TestTrackCaseAllocations(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.MaxLatencySamples = 64
+	handle := gm.RegisterCase("case1")
+	id := gm.TrackGoroutineStart()
+	gm.TrackCase(handle, time.Microsecond, id)
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		gm.TrackCase(handle, time.Microsecond, id)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations per recorded hit, got %v", allocs)
+	}
+
+	caseStats := gm.GetGoroutineStats(id).GetSelectCaseStats("case1")
+	if caseStats.GetCaseHits() != 1002 {
+		t.Errorf("Expected 1002 hits, got %d", caseStats.GetCaseHits())
+	}
+	if caseStats.GetPercentile(99) != time.Microsecond {
+		t.Errorf("Expected p99 %v from the bounded reservoir, got %v", time.Microsecond, caseStats.GetPercentile(99))
+	}
+}
@@ -0,0 +1,171 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+func TestChanRecordsWaits(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	jobs := tracker.NewChan[int](gm, "jobs", 2)
+
+	received := make(chan int)
+	id := goTracked(gm, "", func(id tracker.GoroutineId) {
+		for {
+			v, ok := jobs.Recv()
+			if !ok {
+				close(received)
+				return
+			}
+			received <- v
+		}
+	})
+
+	// the sender is not tracked, only the channel records its sends
+	time.Sleep(5 * time.Millisecond)
+	jobs.Send(1)
+	<-received
+	jobs.Send(2)
+	<-received
+	jobs.Close()
+	<-received
+	gm.Wg.Wait()
+
+	channel := gm.GetChannelStats("jobs")
+	if channel == nil {
+		t.Fatal("Expected stats for the jobs channel")
+	}
+	if channel.Capacity != 2 || channel.Sends != 2 || channel.Receives != 3 {
+		t.Errorf("Expected capacity 2, 2 sends and 3 receives, got %d, %d and %d", channel.Capacity, channel.Sends, channel.Receives)
+	}
+	if channel.RecvWaitTime < 5*time.Millisecond {
+		t.Errorf("Expected the first receive to wait at least 5ms, got %v", channel.RecvWaitTime)
+	}
+	if channel.OccupancySamples != 5 || channel.MaxOccupancy > 1 {
+		t.Errorf("Expected 5 occupancy samples of at most 1 value, got %d with max %d", channel.OccupancySamples, channel.MaxOccupancy)
+	}
+	if !channel.IsClosed() || channel.ClosedBy == 0 || channel.ClosedBy == id {
+		t.Errorf("Expected the channel to be closed by the test goroutine, got %d", channel.ClosedBy)
+	}
+
+	stats := gm.GetGoroutineStats(id)
+	recv := stats.GetSelectCaseStats("jobs" + tracker.ChanRecvSuffix)
+	if recv == nil || recv.GetCaseHits() != 3 || recv.GetCaseTime() != channel.RecvWaitTime {
+		t.Fatalf("Expected the receives to be attributed to the receiving goroutine, got %+v", recv)
+	}
+	if stats.GetSelectCaseStats("jobs"+tracker.ChanSendSuffix) != nil {
+		t.Error("Expected the untracked sender's waits to be left out of the receiving goroutine")
+	}
+	if len(gm.GetAllStats()) != 1 {
+		t.Errorf("Expected only the receiving goroutine to be tracked, got %d goroutines", len(gm.GetAllStats()))
+	}
+	if stats.GetTotalSelectBlockedTime() != channel.RecvWaitTime {
+		t.Errorf("Expected the receive wait to count as blocked time, got %v", stats.GetTotalSelectBlockedTime())
+	}
+
+	channelJSON, ok := gm.Snapshot().JSON("test").Channels["jobs"]
+	if !ok || channelJSON.Sends != 2 || channelJSON.ClosedAt == nil {
+		t.Errorf("Expected the channel in the JSON report, got %+v", channelJSON)
+	}
+}
+
+func TestChanSnapshotDeltaAndReset(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	results := tracker.NewChan[string](gm, "results", 4)
+
+	results.Send("a")
+	results.Send("b")
+	prev := gm.Snapshot()
+	results.Send("c")
+	results.Recv()
+
+	delta := tracker.Delta(prev, gm.Snapshot())
+	channel := delta.Channels["results"]
+	if channel.Sends != 1 || channel.Receives != 1 || channel.MaxOccupancy != 3 {
+		t.Errorf("Expected 1 send, 1 receive and a max occupancy of 3 in the delta, got %+v", channel)
+	}
+
+	gm.SnapshotAndReset()
+	channel = gm.GetChannelStats("results")
+	if channel.Sends != 0 || channel.OccupancySamples != 0 || channel.Capacity != 4 {
+		t.Errorf("Expected the counters to be reset and the capacity kept, got %+v", channel)
+	}
+	if results.Len() != 2 || results.Cap() != 4 {
+		t.Errorf("Expected 2 of 4 values buffered, got %d of %d", results.Len(), results.Cap())
+	}
+}
+
+func TestChanFromTrackedGoroutine(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	jobs := tracker.NewChan[int](gm, "jobs", 1)
+
+	id := gm.TrackGoroutineStart()
+	jobs.SendFrom(1, id)
+	if v, ok := jobs.RecvFrom(id); !ok || v != 1 {
+		t.Fatalf("Expected to receive 1, got %d", v)
+	}
+	jobs.Close()
+	if _, ok := jobs.RecvFrom(id); ok {
+		t.Fatal("Expected the closed channel to report ok false")
+	}
+	gm.TrackGoroutineEnd(id)
+
+	stats := gm.GetGoroutineStats(id)
+	if hits := stats.GetSelectCaseStats("jobs" + tracker.ChanSendSuffix).GetCaseHits(); hits != 1 {
+		t.Errorf("Expected 1 send on the goroutine, got %d", hits)
+	}
+	if hits := stats.GetSelectCaseStats("jobs" + tracker.ChanRecvSuffix).GetCaseHits(); hits != 2 {
+		t.Errorf("Expected 2 receives on the goroutine, got %d", hits)
+	}
+	if stats.GetExitReason() != tracker.ExitChannelClosed {
+		t.Errorf("Expected the closed receive to set the exit reason, got %q", stats.GetExitReason())
+	}
+}
+
+func TestChanWithoutManager(t *testing.T) {
+	jobs := tracker.NewChan[int](nil, "jobs", 1)
+	jobs.Send(1)
+	if v, ok := jobs.Recv(); v != 1 || !ok {
+		t.Errorf("Expected to receive 1, got %d, %v", v, ok)
+	}
+	jobs.Close()
+	if _, ok := jobs.Recv(); ok {
+		t.Error("Expected the closed channel to be drained")
+	}
+}
+
+func TestChanEnabledAfterCreation(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.Enabled = false
+	jobs := tracker.NewChan[int](gm, "jobs", 1)
+	gm.Enabled = true
+
+	jobs.SendFrom(1, 0)
+	if v, ok := jobs.RecvFrom(0); v != 1 || !ok {
+		t.Errorf("Expected to receive 1, got %d, %v", v, ok)
+	}
+	jobs.Close()
+	if gm.GetChannelStats("jobs") != nil {
+		t.Error("Expected a channel created while disabled not to be recorded")
+	}
+}
+
+func TestChanDisabledAfterCreation(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	jobs := tracker.NewChan[int](gm, "jobs", 1)
+	jobs.Send(1)
+	gm.Enabled = false
+
+	jobs.Recv()
+	jobs.Send(2)
+	jobs.Close()
+
+	channel := gm.GetChannelStats("jobs")
+	if channel == nil || channel.Sends != 1 || channel.Receives != 0 || !channel.ClosedAt.IsZero() {
+		t.Errorf("Expected only the send made while enabled, got %+v", channel)
+	}
+}
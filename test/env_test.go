@@ -0,0 +1,112 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+func TestEnvActivation(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	t.Setenv(tracker.EnvEnabled, "true")
+	t.Setenv(tracker.EnvFormat, "json")
+	t.Setenv(tracker.EnvAction, "save")
+	t.Setenv(tracker.EnvOutputDir, dir)
+
+	gm := tracker.NewGoroutineManager()
+	if !gm.Enabled || gm.FileType != "json" || gm.Action != tracker.Save || gm.OutputDir != dir {
+		t.Fatalf("Manager not configured from environment: enabled=%v type=%q action=%q dir=%q",
+			gm.Enabled, gm.FileType, gm.Action, gm.OutputDir)
+	}
+
+	id := gm.TrackGoroutineStart()
+	gm.TrackSelectCase("case1", time.Millisecond, id)
+	gm.TrackGoroutineEnd(id)
+
+	if err := gm.Done(); err != nil {
+		t.Fatalf("Error saving stats: %v", err)
+	}
+	if !tracker.FileExists(filepath.Join(dir, ".internal.json")) {
+		t.Error("Stats were not saved to the output directory")
+	}
+}
+
+func TestEnvDisabled(t *testing.T) {
+	t.Setenv(tracker.EnvEnabled, "0")
+	t.Setenv(tracker.EnvFormat, "json")
+	t.Setenv(tracker.EnvAction, "save")
+	t.Chdir(t.TempDir())
+
+	gm := tracker.NewGoroutineManager()
+	if gm.Enabled {
+		t.Fatal("Expected manager to be disabled")
+	}
+
+	id := gm.TrackGoroutineStart()
+	gm.TrackSelectCase("case1", time.Millisecond, id)
+	gm.TrackGoroutineEnd(id)
+
+	if err := gm.Done(); err != nil {
+		t.Errorf("Error finishing disabled manager: %v", err)
+	}
+	if len(gm.GetAllStats()) != 0 {
+		t.Error("Disabled manager recorded stats")
+	}
+	if tracker.FileExists(".internal.json") {
+		t.Error("Disabled manager wrote a report")
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	id := gm.TrackGoroutineStart()
+	gm.TrackSelectCase("case1", time.Millisecond, id)
+
+	rec := httptest.NewRecorder()
+	gm.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	var stats tracker.JSONStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("Invalid JSON response: %v", err)
+	}
+	if len(stats.Goroutines) != 1 {
+		t.Errorf("Expected 1 goroutine in response, got %d", len(stats.Goroutines))
+	}
+}
+
+func TestEnvHTTPClosedByDone(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error finding a free port: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	t.Setenv(tracker.EnvHTTPAddr, addr)
+	gm := tracker.NewGoroutineManager()
+	gm.Action = tracker.None
+
+	resp, err := http.Get("http://" + addr)
+	if err != nil {
+		t.Fatalf("Error requesting live stats: %v", err)
+	}
+	resp.Body.Close()
+
+	if err := gm.Done(); err != nil {
+		t.Fatalf("Error finishing manager: %v", err)
+	}
+
+	listener, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("Expected Done to free %s: %v", addr, err)
+	}
+	listener.Close()
+}
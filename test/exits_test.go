@@ -0,0 +1,124 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+	"github.com/AlexsanderHamir/IdleSpy/visualization"
+)
+
+func TestExitReasons(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	jobs := tracker.NewChan[int](gm, "jobs", 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ids := make(chan tracker.GoroutineId, 1)
+	run := func(body func(id tracker.GoroutineId)) tracker.GoroutineId {
+		go func() {
+			id := gm.TrackGoroutineStartInGroup("workers")
+			ids <- id
+			body(id)
+		}()
+		id := <-ids
+		gm.Wg.Wait()
+		return id
+	}
+
+	returned := run(func(id tracker.GoroutineId) {
+		gm.TrackGoroutineEnd(id)
+	})
+	cancelled := run(func(id tracker.GoroutineId) {
+		defer gm.TrackGoroutineEnd(id)
+		gm.WatchContext(ctx, id)
+		<-ctx.Done()
+	})
+	jobs.Close()
+	drained := run(func(id tracker.GoroutineId) {
+		defer gm.TrackGoroutineEnd(id)
+		for _, ok := jobs.Recv(); ok; _, ok = jobs.Recv() {
+		}
+	})
+	explicit := run(func(id tracker.GoroutineId) {
+		defer gm.TrackGoroutineEndReason(id, tracker.ExitReturned)
+		gm.WatchContext(ctx, id)
+		<-ctx.Done()
+	})
+
+	expected := map[tracker.GoroutineId]tracker.ExitReason{
+		returned:  tracker.ExitReturned,
+		cancelled: tracker.ExitCancelled,
+		drained:   tracker.ExitChannelClosed,
+		explicit:  tracker.ExitReturned,
+	}
+	for id, reason := range expected {
+		if got := gm.GetGoroutineStats(id).GetExitReason(); got != reason {
+			t.Errorf("Expected goroutine %d to have ended as %s, got %s", id, reason, got)
+		}
+	}
+
+	exits := gm.Snapshot().Exits()["workers"]
+	if exits == nil || exits.GetTotal() != 4 || exits.GetCount(tracker.ExitReturned) != 2 {
+		t.Errorf("Expected 4 exits, 2 of them returned, got %+v", exits)
+	}
+}
+
+func TestPanicExitReason(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	gm := tracker.NewGoroutineManager()
+	gm.FileType = "json"
+	gm.Action = tracker.Save
+	gm.MaxFinishedGoroutines = 1
+
+	for range 2 {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			defer func() {
+				recover()
+			}()
+
+			id := gm.TrackGoroutineStartInGroup("workers")
+			defer gm.RecoverAndFlush(id)
+			panic("worker died")
+		}()
+		<-done
+	}
+
+	group := gm.GetAllGroupStats()["workers"]
+	if group == nil || group.Exits.GetCount(tracker.ExitPanicked) != 1 || len(group.Exits.Panics) != 1 {
+		t.Fatalf("Expected the rolled up goroutine's panic to be kept, got %+v", group)
+	}
+	if p := group.Exits.Panics[0]; p.Value != "worker died" || !strings.Contains(p.Stack, "TestPanicExitReason") {
+		t.Errorf("Expected the panic value and the panicking stack, got %+v", p)
+	}
+
+	data, err := os.ReadFile(".internal.json")
+	if err != nil {
+		t.Fatalf("Error reading flushed stats: %v", err)
+	}
+	var stats tracker.JSONStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		t.Fatalf("Error parsing flushed stats: %v", err)
+	}
+	for _, goroutineJSON := range stats.Goroutines {
+		if goroutineJSON.ExitReason != tracker.ExitPanicked || goroutineJSON.Panic == nil || goroutineJSON.Panic.Value != "worker died" {
+			t.Errorf("Expected the kept goroutine's panic in the JSON report, got %s %+v", goroutineJSON.ExitReason, goroutineJSON.Panic)
+		}
+	}
+
+	groups, err := visualization.ParseJSONToExits(data)
+	if err != nil {
+		t.Fatalf("Error parsing exits: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Total != 2 || groups[0].Reasons["panicked"] != 2 || len(groups[0].Panics) != 2 {
+		t.Errorf("Expected 2 panicked workers with their panics, got %+v", groups)
+	}
+}
@@ -0,0 +1,14 @@
+// Package sitea records a select site from site.go, like package siteb at
+// the same line, to check that sites in different packages stay apart.
+package sitea
+
+import (
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+// Record records won as the case that won the select in this file
+func Record(gm *tracker.GoroutineManager, won string, id tracker.GoroutineId) {
+	gm.TrackSiteCase("", won, time.Millisecond, id)
+}
@@ -0,0 +1,152 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+	"github.com/AlexsanderHamir/IdleSpy/visualization"
+)
+
+func TestTrackSelectCaseHandled(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	id := gm.TrackGoroutineStart()
+
+	gm.TrackSelectCaseHandled("received", 10*time.Millisecond, 30*time.Millisecond, id)
+	gm.TrackSelectCaseHandled("received", 20*time.Millisecond, 10*time.Millisecond, id)
+	gm.TrackSelectCase("timeout", 5*time.Millisecond, id)
+
+	stats := gm.GetGoroutineStats(id)
+	received := stats.GetSelectCaseStats("received")
+	if received.GetCaseTime() != 30*time.Millisecond {
+		t.Errorf("Expected 30ms wait time, got %v", received.GetCaseTime())
+	}
+	if received.GetHandlingTime() != 40*time.Millisecond {
+		t.Errorf("Expected 40ms handling time, got %v", received.GetHandlingTime())
+	}
+	if received.GetPercentile(100) != 20*time.Millisecond {
+		t.Errorf("Expected percentiles to cover the wait time only, got max %v", received.GetPercentile(100))
+	}
+	if stats.GetTotalHandlingTime() != 40*time.Millisecond {
+		t.Errorf("Expected 40ms total handling time, got %v", stats.GetTotalHandlingTime())
+	}
+
+	handle := gm.RegisterCase("registered")
+	gm.TrackCaseHandled(handle, time.Millisecond, 2*time.Millisecond, id)
+	if handling := stats.GetSelectCaseStats("registered").GetHandlingTime(); handling != 2*time.Millisecond {
+		t.Errorf("Expected 2ms handling time through the handle, got %v", handling)
+	}
+
+	jsonStats := gm.Snapshot().JSON("test")
+	goroutine := jsonStats.Goroutines[jsonKey(id)]
+	if goroutine.SelectCaseStats["received"].TotalHandling != 40*time.Millisecond {
+		t.Errorf("Expected handling time in the JSON case, got %v", goroutine.SelectCaseStats["received"].TotalHandling)
+	}
+	if goroutine.TotalHandling != 42*time.Millisecond {
+		t.Errorf("Expected 42ms handling time in the JSON goroutine, got %v", goroutine.TotalHandling)
+	}
+}
+
+func TestScoreUsesBusyTime(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	id := gm.TrackGoroutineStart()
+	gm.TrackSelectCaseHandled("received", 0, 5*time.Millisecond, id)
+	time.Sleep(20 * time.Millisecond)
+	gm.TrackGoroutineEnd(id)
+
+	data, err := json.Marshal(gm.Snapshot().JSON("test"))
+	if err != nil {
+		t.Fatalf("Error marshaling stats: %v", err)
+	}
+	stats, err := visualization.ParseJSONToGoroutineStats(data)
+	if err != nil {
+		t.Fatalf("Error parsing stats: %v", err)
+	}
+
+	want := float64(5*time.Millisecond) / float64(stats[0].Lifetime)
+	if math.Abs(stats[0].Efficiency-want) > 1e-9 {
+		t.Errorf("Expected efficiency %.3f from the busy time, got %.3f", want, stats[0].Efficiency)
+	}
+}
+
+// jsonKey returns the key a goroutine is stored under in the JSON report
+func jsonKey(id tracker.GoroutineId) string {
+	data, _ := json.Marshal(int(id))
+	return string(data)
+}
+
+func TestScoreWithoutWork(t *testing.T) {
+	data := []byte(`{"goroutines":{
+		"1":{"lifetime":1000,"total_select_blocked_time":100,"working_time":0},
+		"2":{"lifetime":1000,"total_select_blocked_time":100}
+	}}`)
+
+	stats, err := visualization.ParseJSONToGoroutineStats(data)
+	if err != nil {
+		t.Fatalf("Error parsing stats: %v", err)
+	}
+
+	scores := make(map[int]float64, len(stats))
+	for _, s := range stats {
+		scores[s.ID] = s.Efficiency
+	}
+	if scores[1] != 0 {
+		t.Errorf("Expected a goroutine that did no work to score 0, got %.3f", scores[1])
+	}
+	if math.Abs(scores[2]-0.9) > 1e-9 {
+		t.Errorf("Expected a report without working time to fall back to 0.900, got %.3f", scores[2])
+	}
+}
+
+func TestScoreOfSelectOnlyGoroutine(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	id := gm.TrackGoroutineStart()
+	gm.TrackSelectCase("received", 5*time.Millisecond, id)
+	time.Sleep(20 * time.Millisecond)
+	gm.TrackGoroutineEnd(id)
+
+	data, err := json.Marshal(gm.Snapshot().JSON("test"))
+	if err != nil {
+		t.Fatalf("Error marshaling stats: %v", err)
+	}
+	if strings.Contains(string(data), `"working_time"`) {
+		t.Errorf("Expected no working time for a goroutine without spans or handling time, got %s", data)
+	}
+
+	stats, err := visualization.ParseJSONToGoroutineStats(data)
+	if err != nil {
+		t.Fatalf("Error parsing stats: %v", err)
+	}
+	want := 1 - float64(5*time.Millisecond)/float64(stats[0].Lifetime)
+	if math.Abs(stats[0].Efficiency-want) > 1e-9 {
+		t.Errorf("Expected efficiency %.3f from the blocked time, got %.3f", want, stats[0].Efficiency)
+	}
+}
+
+func TestTimelineScoreUsesBusyTime(t *testing.T) {
+	data := []byte(`{"time":"2026-01-01T00:00:00Z","goroutines":{
+		"1":{"lifetime":1000,"total_select_blocked_time":100,"working_time":100},
+		"2":{"lifetime":1000,"total_select_blocked_time":100}
+	}}`)
+	data = bytes.ReplaceAll(data, []byte("\n"), nil)
+
+	points, err := visualization.ParseStreamToTimeline(data)
+	if err != nil {
+		t.Fatalf("Error parsing stream: %v", err)
+	}
+	if len(points) != 1 {
+		t.Fatalf("Expected 1 point, got %d", len(points))
+	}
+	if points[0].Busy != 1000 {
+		t.Errorf("Expected busy time 1000 from the working and not blocked time, got %d", points[0].Busy)
+	}
+	if math.Abs(points[0].Efficiency-0.5) > 1e-9 {
+		t.Errorf("Expected efficiency 0.500, got %.3f", points[0].Efficiency)
+	}
+}
@@ -35,3 +35,54 @@ func CheckStatsAccuracy(t *testing.T, stats *tracker.GoroutineStats, latency1 ti
 		t.Errorf("Expected total select time %v, got %v", expectedTime, totalTime)
 	}
 }
+
+// goTracked runs fn on a new goroutine tracked in group and returns the
+// goroutine's id once it started, gm.Wg.Wait then waits for it to end
+func goTracked(gm *tracker.GoroutineManager, group string, fn func(id tracker.GoroutineId)) tracker.GoroutineId {
+	ids := make(chan tracker.GoroutineId)
+	go func() {
+		id := gm.TrackGoroutineStartInGroup(group)
+		defer gm.TrackGoroutineEnd(id)
+		ids <- id
+		fn(id)
+	}()
+	return <-ids
+}
+
+// runTracked runs fn on a new goroutine tracked in group, waits for the
+// manager's goroutines to end and returns the goroutine's id
+func runTracked(gm *tracker.GoroutineManager, group string, fn func(id tracker.GoroutineId)) tracker.GoroutineId {
+	id := goTracked(gm, group, fn)
+	gm.Wg.Wait()
+	return id
+}
+
+// trackCases records one hit of a millisecond for each case, in order, from a
+// new goroutine tracked in the default group and returns its id once it ended
+func trackCases(gm *tracker.GoroutineManager, caseNames ...string) tracker.GoroutineId {
+	return runTracked(gm, "", func(id tracker.GoroutineId) {
+		for _, caseName := range caseNames {
+			gm.TrackSelectCase(caseName, time.Millisecond, id)
+		}
+	})
+}
+
+// checkCase checks the hits, blocked time and median latency of a select case
+func checkCase(t *testing.T, stats *tracker.GoroutineStats, caseName string, hits int, blocked, p50 time.Duration) {
+	t.Helper()
+
+	caseStats := stats.GetSelectCaseStats(caseName)
+	if caseStats == nil {
+		t.Errorf("Expected select case %q to be recorded", caseName)
+		return
+	}
+	if caseStats.GetCaseHits() != hits {
+		t.Errorf("Expected %d hits for %q, got %d", hits, caseName, caseStats.GetCaseHits())
+	}
+	if caseStats.GetCaseTime() != blocked {
+		t.Errorf("Expected blocked time %v for %q, got %v", blocked, caseName, caseStats.GetCaseTime())
+	}
+	if caseStats.GetPercentile(50) != p50 {
+		t.Errorf("Expected p50 %v for %q, got %v", p50, caseName, caseStats.GetPercentile(50))
+	}
+}
@@ -0,0 +1,82 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+	"github.com/AlexsanderHamir/IdleSpy/visualization"
+)
+
+func TestBlockedCategories(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.SetCaseKind("item_received", tracker.KindReceive)
+	gm.SetCaseKind("tick", tracker.KindTimer)
+	gm.SetCaseKind("ctx_done", tracker.KindContextDone)
+
+	id := gm.TrackGoroutineStartInGroup("workers")
+	gm.TrackSelectCase("item_received", 4*time.Millisecond, id)
+	gm.TrackSiteCase("worker.run", "tick", 3*time.Millisecond, id)
+	gm.TrackSelectCase("ctx_done", 2*time.Millisecond, id)
+	gm.TrackSelectCase("result_sent", time.Millisecond, id)
+	gm.TrackNestedSelectCase("item_received", "result_sent", time.Millisecond, id)
+
+	stats := gm.GetGoroutineStats(id)
+	categories := stats.GetBlockedCategories()
+	if categories.InputStarved != 4*time.Millisecond || categories.TimerIdle != 3*time.Millisecond || categories.Cancellation != 2*time.Millisecond {
+		t.Errorf("Expected 4ms starved, 3ms timer-idle and 2ms cancellation, got %+v", categories)
+	}
+	if categories.Unclassified != time.Millisecond || categories.GetTotal() != stats.GetTotalSelectBlockedTime() {
+		t.Errorf("Expected the unkinded case to be unclassified and the nested one left out, got %+v", categories)
+	}
+
+	// kinds set later apply to the cases already recorded
+	gm.SetCaseKind("result_sent", tracker.KindSend)
+	categories = gm.GetGoroutineStats(id).GetBlockedCategories()
+	if categories.OutputBackpressured != time.Millisecond || categories.Unclassified != 0 {
+		t.Errorf("Expected result_sent to become backpressure, got %+v", categories)
+	}
+	if kind := stats.GetSelectCaseStats("item_received" + tracker.NestedCaseSeparator + "result_sent").Kind; kind != tracker.KindSend {
+		t.Errorf("Expected the nested case to be kinded by its own name, got %q", kind)
+	}
+
+	goroutineJSON := gm.Snapshot().JSON("test").Goroutines[jsonKey(id)]
+	if goroutineJSON.Categories.TimerIdle != 3*time.Millisecond || goroutineJSON.SelectCaseStats["ctx_done"].Kind != tracker.KindContextDone {
+		t.Errorf("Expected the categories and kinds in the JSON report, got %+v", goroutineJSON.Categories)
+	}
+}
+
+func TestChanCasesHaveKinds(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	out := tracker.NewChan[int](gm, "out", 1)
+
+	id := gm.TrackGoroutineStartInGroup("producers")
+	out.Send(1)
+	out.Recv()
+
+	stats := gm.GetGoroutineStats(id)
+	if kind := stats.GetSelectCaseStats("out" + tracker.ChanSendSuffix).Kind; kind != tracker.KindSend {
+		t.Errorf("Expected channel sends to be send cases, got %q", kind)
+	}
+	if kind := stats.GetSelectCaseStats("out" + tracker.ChanRecvSuffix).Kind; kind != tracker.KindReceive {
+		t.Errorf("Expected channel receives to be receive cases, got %q", kind)
+	}
+
+	data, err := json.Marshal(gm.Snapshot().JSON("test"))
+	if err != nil {
+		t.Fatalf("Error marshaling stats: %v", err)
+	}
+	groups, err := visualization.ParseJSONToCategories(data)
+	if err != nil {
+		t.Fatalf("Error parsing categories: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Group != "producers" || groups[0].Goroutines != 1 {
+		t.Fatalf("Expected one producers group, got %+v", groups)
+	}
+	if groups[0].Total() != stats.GetTotalSelectBlockedTime() {
+		t.Errorf("Expected the group total %v to match the goroutine's blocked time %v", groups[0].Total(), stats.GetTotalSelectBlockedTime())
+	}
+}
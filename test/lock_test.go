@@ -0,0 +1,125 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+func TestMutexRecordsWaitAndHold(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	mu := &tracker.Mutex{Name: "cache", Manager: gm}
+
+	mu.Lock()
+	id := goTracked(gm, "", func(id tracker.GoroutineId) {
+		mu.Lock()
+		time.Sleep(2 * time.Millisecond)
+		mu.Unlock()
+	})
+	time.Sleep(5 * time.Millisecond)
+	mu.Unlock()
+	gm.Wg.Wait()
+
+	lock := gm.GetLockStats("cache")
+	if lock == nil || lock.Locks != 2 || lock.RLocks != 0 {
+		t.Fatalf("Expected 2 write acquisitions of cache, got %+v", lock)
+	}
+	if lock.MaxWait < 3*time.Millisecond || lock.MaxHold < 5*time.Millisecond {
+		t.Errorf("Expected a wait of at least 3ms and a hold of at least 5ms, got %v and %v", lock.MaxWait, lock.MaxHold)
+	}
+
+	stats := gm.GetGoroutineStats(id)
+	caseStats := stats.GetSelectCaseStats("cache" + tracker.LockCaseSuffix)
+	if caseStats == nil || caseStats.GetCaseHits() != 1 {
+		t.Fatalf("Expected the acquisition to be attributed to the tracked goroutine, got %+v", caseStats)
+	}
+	if caseStats.GetCaseTime() < 3*time.Millisecond || caseStats.GetHandlingTime() < 2*time.Millisecond {
+		t.Errorf("Expected the wait as blocked time and the hold as handling time, got %v and %v",
+			caseStats.GetCaseTime(), caseStats.GetHandlingTime())
+	}
+	if len(gm.GetAllStats()) != 1 {
+		t.Errorf("Expected the untracked goroutine's acquisition to stay out of the goroutine stats, got %d goroutines", len(gm.GetAllStats()))
+	}
+
+	lockJSON, ok := gm.Snapshot().JSON("test").Locks["cache"]
+	if !ok || lockJSON.Locks != 2 || lockJSON.TotalHoldTime != lock.HoldTime {
+		t.Errorf("Expected the lock in the JSON report, got %+v", lockJSON)
+	}
+}
+
+func TestRWMutexRecordsReaders(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	rw := &tracker.RWMutex{Name: "config", Manager: gm}
+
+	id := gm.TrackGoroutineStart()
+	rw.RLock()
+	rw.RLock()
+	time.Sleep(time.Millisecond)
+	rw.RUnlock()
+	rw.RUnlock()
+
+	if !rw.TryLock() {
+		t.Fatal("Expected TryLock to succeed once the readers left")
+	}
+	if rw.TryRLock() {
+		t.Fatal("Expected TryRLock to fail while the write lock is held")
+	}
+	rw.Unlock()
+
+	lock := gm.GetLockStats("config")
+	if lock.RLocks != 2 || lock.Locks != 1 {
+		t.Errorf("Expected 2 read locks next to 1 write lock, got %d and %d", lock.RLocks, lock.Locks)
+	}
+	if lock.HoldTime < 2*time.Millisecond {
+		t.Errorf("Expected both read holds to last until their RUnlock, got %v", lock.HoldTime)
+	}
+
+	stats := gm.GetGoroutineStats(id)
+	if stats.GetSelectCaseStats("config"+tracker.RLockCaseSuffix) == nil || stats.GetSelectCaseStats("config"+tracker.LockCaseSuffix) == nil {
+		t.Error("Expected read and write acquisitions to be recorded as separate cases")
+	}
+}
+
+func TestMutexWithoutManager(t *testing.T) {
+	var mu tracker.Mutex
+	mu.Lock()
+	if mu.TryLock() {
+		t.Fatal("Expected TryLock to fail while locked")
+	}
+	mu.Unlock()
+
+	var rw tracker.RWMutex
+	rw.RLock()
+	rw.RUnlock()
+	rw.Lock()
+	rw.Unlock()
+}
+
+func TestRWMutexReleasedByAnotherGoroutine(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	rw := &tracker.RWMutex{Name: "handoff", Manager: gm}
+
+	id := gm.TrackGoroutineStart()
+	rw.RLockFrom(id)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rw.RUnlock()
+	}()
+	<-done
+
+	rw.LockFrom(id)
+	rw.Unlock()
+
+	lock := gm.GetLockStats("handoff")
+	if lock.RLocks != 1 || lock.Locks != 1 {
+		t.Errorf("Expected the handed off read lock to be recorded, got %d read and %d write locks", lock.RLocks, lock.Locks)
+	}
+	if hits := gm.GetGoroutineStats(id).GetSelectCaseStats("handoff" + tracker.RLockCaseSuffix).GetCaseHits(); hits != 1 {
+		t.Errorf("Expected the read lock on the goroutine that took it, got %d hits", hits)
+	}
+}
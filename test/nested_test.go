@@ -0,0 +1,96 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/sharedtypes"
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+	"github.com/AlexsanderHamir/IdleSpy/visualization"
+)
+
+func TestNestedSelectCases(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	id := runTracked(gm, "", func(id tracker.GoroutineId) {
+		// the outer measurement includes the inner select's wait
+		gm.TrackNestedSelectCase("item_received", "result_sent", 30*time.Millisecond, id)
+		gm.TrackSelectCase("item_received", 50*time.Millisecond, id)
+		gm.TrackSelectCase("timeout", 10*time.Millisecond, id)
+		// a nested case whose parent was never recorded still counts
+		gm.TrackNestedSelectCase("unrecorded", "orphan", 5*time.Millisecond, id)
+	})
+
+	stats := gm.GetGoroutineStats(id)
+	nestedName := "item_received" + tracker.NestedCaseSeparator + "result_sent"
+	orphanName := "unrecorded" + tracker.NestedCaseSeparator + "orphan"
+	checkCase(t, stats, nestedName, 1, 30*time.Millisecond, 30*time.Millisecond)
+	checkCase(t, stats, "item_received", 1, 50*time.Millisecond, 50*time.Millisecond)
+	checkCase(t, stats, "timeout", 1, 10*time.Millisecond, 10*time.Millisecond)
+	checkCase(t, stats, orphanName, 1, 5*time.Millisecond, 5*time.Millisecond)
+	if parent := stats.GetSelectCaseStats(nestedName).Parent; parent != "item_received" {
+		t.Errorf("Expected the nested case under item_received, got %q", parent)
+	}
+
+	if total := stats.GetTotalSelectBlockedTime(); total != 65*time.Millisecond {
+		t.Errorf("Expected nested time not to be double counted, got total %v", total)
+	}
+	if self := stats.GetSelfBlockedTime("item_received"); self != 20*time.Millisecond {
+		t.Errorf("Expected 20ms self time for the parent, got %v", self)
+	}
+
+	cases := gm.Snapshot().JSON("test").Goroutines[jsonKey(id)].SelectCaseStats
+	if cases[nestedName].Parent != "item_received" {
+		t.Errorf("Expected the parent in the JSON case, got %q", cases[nestedName].Parent)
+	}
+	if cases["item_received"].SelfBlockedTime != 20*time.Millisecond {
+		t.Errorf("Expected 20ms self time in the JSON case, got %v", cases["item_received"].SelfBlockedTime)
+	}
+
+	data, err := json.Marshal(gm.Snapshot().JSON("test"))
+	if err != nil {
+		t.Fatalf("Error marshaling stats: %v", err)
+	}
+	goroutines, err := visualization.ParseJSONToGoroutineStats(data)
+	if err != nil {
+		t.Fatalf("Error parsing stats: %v", err)
+	}
+	if len(goroutines) != 1 || goroutines[0].TotalBlockedTime != 65*time.Millisecond {
+		t.Errorf("Expected the score chart to use the deduplicated total, got %v", goroutines[0].TotalBlockedTime)
+	}
+}
+
+func TestFoldedNestedCasesKeepParent(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.MaxCaseNamesPerGoroutine = 1
+	id := runTracked(gm, "", func(id tracker.GoroutineId) {
+		gm.TrackSelectCase("outer", 50*time.Millisecond, id)
+		gm.TrackNestedSelectCase("outer", "inner", 30*time.Millisecond, id)
+	})
+
+	stats := gm.GetGoroutineStats(id)
+	foldedName := "outer" + tracker.NestedCaseSeparator + tracker.OtherCaseName
+	checkCase(t, stats, "outer", 1, 50*time.Millisecond, 50*time.Millisecond)
+	checkCase(t, stats, foldedName, 1, 30*time.Millisecond, 30*time.Millisecond)
+	if parent := stats.GetSelectCaseStats(foldedName).Parent; parent != "outer" {
+		t.Errorf("Expected the folded nested case to stay under its parent, got %q", parent)
+	}
+	if total := stats.GetTotalSelectBlockedTime(); total != 50*time.Millisecond {
+		t.Errorf("Expected folded nested time not to be double counted, got total %v", total)
+	}
+}
+
+func TestMaxValueAfterNesting(t *testing.T) {
+	// nesting can move a case ahead of a larger one
+	cases := []*sharedtypes.CaseJSON{
+		{CaseName: "outer", Hits: 2},
+		{CaseName: "outer" + tracker.NestedCaseSeparator + "inner", Parent: "outer", Hits: 5},
+		{CaseName: "other", Hits: 3},
+	}
+
+	if got := tracker.GetMaxValue(cases, sharedtypes.TotalHits); got != 5 {
+		t.Errorf("Expected the max across all cases to be 5, got %v", got)
+	}
+}
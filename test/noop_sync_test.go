@@ -0,0 +1,132 @@
+package test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestNoopAPIInSync checks that building with the idlespy_off tag exposes
+// exactly the same exported API as the real tracker
+func TestNoopAPIInSync(t *testing.T) {
+	real := trackerAPI(t)
+	noop := trackerAPI(t, "idlespy_off")
+
+	for name, signature := range real {
+		noopSignature, ok := noop[name]
+		if !ok {
+			t.Errorf("%s is missing from the idlespy_off build", name)
+			continue
+		}
+		if noopSignature != signature {
+			t.Errorf("%s differs in the idlespy_off build:\n  real: %s\n  noop: %s", name, signature, noopSignature)
+		}
+	}
+
+	for name := range noop {
+		if _, ok := real[name]; !ok {
+			t.Errorf("%s only exists in the idlespy_off build", name)
+		}
+	}
+}
+
+// TestNoopBuilds checks that the module still compiles with the idlespy_off tag
+func TestNoopBuilds(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not available")
+	}
+
+	cmd := exec.Command(goTool, "vet", "-tags", "idlespy_off", "../tracker", "../visualization", "../cmd/...")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("idlespy_off build failed: %v\n%s", err, output)
+	}
+}
+
+// trackerAPI returns the exported declarations of the tracker package built
+// with the given tags, keyed by name and mapped to their signature
+func trackerAPI(t *testing.T, tags ...string) map[string]string {
+	t.Helper()
+
+	ctx := build.Default
+	ctx.BuildTags = tags
+	pkg, err := ctx.ImportDir(filepath.Join("..", "tracker"), 0)
+	if err != nil {
+		t.Fatalf("Error loading tracker package: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	api := make(map[string]string)
+	for _, name := range pkg.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, 0)
+		if err != nil {
+			t.Fatalf("Error parsing %s: %v", name, err)
+		}
+
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if !d.Name.IsExported() {
+					continue
+				}
+				key := "func " + d.Name.Name
+				if d.Recv != nil {
+					recv := receiverName(d.Recv.List[0].Type)
+					if !ast.IsExported(recv) {
+						continue
+					}
+					key = "method " + recv + "." + d.Name.Name
+				}
+				api[key] = nodeString(fset, d.Type)
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						if s.Name.IsExported() {
+							api["type "+s.Name.Name] = nodeString(fset, s.Type)
+						}
+					case *ast.ValueSpec:
+						for _, n := range s.Names {
+							if n.IsExported() {
+								api[d.Tok.String()+" "+n.Name] = nodeString(fset, s.Type)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return api
+}
+
+// receiverName returns the type name of a method receiver
+func receiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	case *ast.IndexExpr:
+		return receiverName(e.X)
+	case *ast.IndexListExpr:
+		return receiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	default:
+		return ""
+	}
+}
+
+func nodeString(fset *token.FileSet, node ast.Node) string {
+	if node == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, node)
+	return buf.String()
+}
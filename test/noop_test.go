@@ -0,0 +1,46 @@
+//go:build idlespy_off
+
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+func TestNoopRecordsNothing(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	jobs := tracker.NewChan[int](gm, "jobs", 1)
+
+	id := runTracked(gm, "workers", func(id tracker.GoroutineId) {
+		gm.TrackSelectCase("received", time.Millisecond, id)
+		gm.BeginSpan("work", id).End()
+		jobs.SendFrom(1, id)
+		if v, ok := jobs.RecvFrom(id); v != 1 || !ok {
+			t.Errorf("Expected the channel to deliver 1, got %d, %v", v, ok)
+		}
+	})
+
+	if id != 0 {
+		t.Errorf("Expected goroutine id 0, got %d", id)
+	}
+	if stats := gm.GetGoroutineStats(id); stats != nil {
+		t.Errorf("Expected no goroutine stats, got %+v", stats)
+	}
+	if n := len(gm.GetAllStats()); n != 0 {
+		t.Errorf("Expected no goroutines, got %d", n)
+	}
+	if n := len(gm.GetAllGroupStats()); n != 0 {
+		t.Errorf("Expected no groups, got %d", n)
+	}
+	if channel := gm.GetChannelStats("jobs"); channel != nil {
+		t.Errorf("Expected no channel stats, got %+v", channel)
+	}
+	if n := len(gm.Snapshot().Stats); n != 0 {
+		t.Errorf("Expected an empty snapshot, got %d goroutines", n)
+	}
+	if err := gm.Done(); err != nil {
+		t.Errorf("Expected Done to succeed, got %v", err)
+	}
+}
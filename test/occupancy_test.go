@@ -0,0 +1,112 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+	"github.com/AlexsanderHamir/IdleSpy/visualization"
+)
+
+func TestOccupancySampling(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	queue := tracker.NewChan[int](gm, "queue", 2)
+	tracker.NewChan[int](gm, "unbuffered", 0)
+
+	if err := gm.StartOccupancySampling(time.Millisecond); err != nil {
+		t.Fatalf("Error starting occupancy sampling: %v", err)
+	}
+	if err := gm.StartOccupancySampling(time.Millisecond); err == nil {
+		t.Error("Expected starting occupancy sampling twice to fail")
+	}
+
+	queue.Send(1)
+	queue.Send(2)
+	time.Sleep(20 * time.Millisecond)
+	queue.Recv()
+	queue.Recv()
+	time.Sleep(20 * time.Millisecond)
+	gm.StopOccupancySampling()
+
+	occupancy := gm.GetChannelStats("queue").Occupancy
+	if occupancy == nil || occupancy.Samples == 0 {
+		t.Fatal("Expected occupancy samples for the buffered channel")
+	}
+	if occupancy.MinFill != 0 || occupancy.MaxFill != 1 {
+		t.Errorf("Expected the fill to range from 0 to 1, got %.2f to %.2f", occupancy.MinFill, occupancy.MaxFill)
+	}
+	if occupancy.TimeAtFull < 10*time.Millisecond || occupancy.TimeAtEmpty < 10*time.Millisecond {
+		t.Errorf("Expected at least 10ms at full and at empty, got %v and %v", occupancy.TimeAtFull, occupancy.TimeAtEmpty)
+	}
+	if mean := occupancy.GetMeanFill(); mean <= 0 || mean >= 1 {
+		t.Errorf("Expected a mean fill between 0 and 1, got %.2f", mean)
+	}
+	if gm.GetChannelStats("unbuffered").Occupancy != nil {
+		t.Error("Expected unbuffered channels not to be sampled")
+	}
+
+	data, err := json.Marshal(gm.Snapshot().JSON("test"))
+	if err != nil {
+		t.Fatalf("Error marshaling stats: %v", err)
+	}
+	channels, err := visualization.ParseJSONToOccupancy(data)
+	if err != nil {
+		t.Fatalf("Error parsing occupancy: %v", err)
+	}
+	if len(channels) != 1 || channels[0].Name != "queue" || len(channels[0].Series) == 0 {
+		t.Fatalf("Expected the queue's occupancy series in the JSON report, got %+v", channels)
+	}
+	if line := []rune(channels[0].Sparkline(10)); len(line) == 0 || len(line) > 10 || line[0] != '█' {
+		t.Errorf("Expected a sparkline of at most 10 columns starting full, got %q", string(line))
+	}
+}
+
+func TestOccupancySeriesBounded(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	queue := tracker.NewChan[int](gm, "queue", 1)
+	queue.Send(1)
+
+	if err := gm.StartOccupancySampling(500 * time.Microsecond); err != nil {
+		t.Fatalf("Error starting occupancy sampling: %v", err)
+	}
+	time.Sleep(400 * time.Millisecond)
+	gm.StopOccupancySampling()
+
+	occupancy := gm.GetChannelStats("queue").Occupancy
+	if occupancy.Samples < 300 {
+		t.Skipf("Only %d samples were taken, the ticker is too coarse", occupancy.Samples)
+	}
+	if len(occupancy.Series) >= 256 {
+		t.Errorf("Expected the series to stay under 256 points, got %d for %d samples", len(occupancy.Series), occupancy.Samples)
+	}
+	for _, p := range occupancy.Series {
+		if p.Fill != 1 {
+			t.Fatalf("Expected every point of a full channel to be full, got %.2f", p.Fill)
+		}
+	}
+}
+
+func TestWatchChannel(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	results := make(chan int, 2)
+	gm.WatchChannel("results", func() int { return len(results) }, cap(results))
+
+	if err := gm.StartOccupancySampling(time.Millisecond); err != nil {
+		t.Fatalf("Error starting occupancy sampling: %v", err)
+	}
+	results <- 1
+	results <- 2
+	time.Sleep(20 * time.Millisecond)
+	gm.StopOccupancySampling()
+
+	channel := gm.GetChannelStats("results")
+	if channel == nil || channel.Capacity != 2 {
+		t.Fatalf("Expected the plain channel to be registered with capacity 2, got %+v", channel)
+	}
+	if channel.Occupancy == nil || channel.Occupancy.MaxFill != 1 {
+		t.Errorf("Expected the full plain channel to be sampled, got %+v", channel.Occupancy)
+	}
+}
@@ -0,0 +1,104 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+	"github.com/AlexsanderHamir/IdleSpy/visualization"
+)
+
+func TestTrackerOverhead(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.MeasureOverhead = true
+	id := gm.TrackGoroutineStart()
+	for range 100 {
+		gm.TrackSelectCase("case1", time.Millisecond, id)
+	}
+	gm.TrackGoroutineEnd(id)
+
+	stats := gm.GetGoroutineStats(id)
+	if stats.GetTrackerOverhead() <= 0 {
+		t.Fatal("Expected tracker overhead to be measured")
+	}
+	if stats.GetTrackerOverhead() > stats.GetGoroutineLifetime() {
+		t.Errorf("Overhead %v exceeds lifetime %v", stats.GetTrackerOverhead(), stats.GetGoroutineLifetime())
+	}
+
+	jsonStats := tracker.NewJSONStats(gm.GetAllStats(), "test")
+	if jsonStats.TotalTrackerOverhead != stats.GetTrackerOverhead() {
+		t.Errorf("Expected total overhead %v in JSON, got %v", stats.GetTrackerOverhead(), jsonStats.TotalTrackerOverhead)
+	}
+
+	unmeasured := tracker.NewGoroutineManager()
+	id = unmeasured.TrackGoroutineStart()
+	unmeasured.TrackSelectCase("case1", time.Millisecond, id)
+	if overhead := unmeasured.GetGoroutineStats(id).GetTrackerOverhead(); overhead != 0 {
+		t.Errorf("Expected no overhead without MeasureOverhead, got %v", overhead)
+	}
+}
+
+func TestOverheadRatio(t *testing.T) {
+	input := visualization.JSONStats{
+		Goroutines: map[string]visualization.GoroutineJSON{
+			"1": {Lifetime: int64(time.Second), TrackerOverhead: int64(50 * time.Millisecond)},
+			"2": {Lifetime: int64(time.Second), TrackerOverhead: int64(time.Millisecond)},
+		},
+	}
+
+	ratio, exceeding := visualization.OverheadRatio(input)
+	if math.Abs(ratio-0.0255) > 1e-9 {
+		t.Errorf("Expected overhead ratio 0.0255, got %v", ratio)
+	}
+	if exceeding != 1 {
+		t.Errorf("Expected 1 goroutine above the threshold, got %d", exceeding)
+	}
+
+	input.Groups = map[string]visualization.GroupJSON{
+		"workers": {Goroutines: 3, TotalLifetime: int64(3 * time.Second), TrackerOverhead: int64(249 * time.Millisecond)},
+	}
+	ratio, exceeding = visualization.OverheadRatio(input)
+	if math.Abs(ratio-0.06) > 1e-9 {
+		t.Errorf("Expected overhead ratio 0.06 with the group, got %v", ratio)
+	}
+	if exceeding != 4 {
+		t.Errorf("Expected 4 goroutines above the threshold, got %d", exceeding)
+	}
+}
+
+func TestTrackerOverheadRolledUp(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.MeasureOverhead = true
+	gm.MaxFinishedGoroutines = 1
+
+	var total time.Duration
+	for range 3 {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			id := gm.TrackGoroutineStart()
+			gm.TrackSelectCase("case1", time.Millisecond, id)
+			gm.TrackGoroutineEnd(id)
+		}()
+		<-done
+	}
+
+	snap := gm.Snapshot()
+	for _, stats := range snap.Stats {
+		total += stats.GetTrackerOverhead()
+	}
+	if len(snap.Groups) == 0 {
+		t.Fatal("Expected finished goroutines to be rolled up")
+	}
+	grouped := tracker.GetTotalGroupOverhead(snap.Groups)
+	if grouped <= 0 {
+		t.Fatal("Expected rolled up goroutines to keep their overhead")
+	}
+
+	if got := snap.JSON("test").TotalTrackerOverhead; got != total+grouped {
+		t.Errorf("Expected total overhead %v including groups, got %v", total+grouped, got)
+	}
+}
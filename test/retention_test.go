@@ -0,0 +1,85 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+func TestRetentionRollsUpFinishedGoroutines(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.MaxFinishedGoroutines = 3
+
+	const goroutines = 200
+	for i := range goroutines {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			id := gm.TrackGoroutineStartInGroup("requests")
+			gm.TrackSelectCase("received", time.Duration(i)*time.Microsecond, id)
+			gm.TrackGoroutineEnd(id)
+		}()
+		<-done
+	}
+	gm.Wg.Wait()
+
+	stats := gm.GetAllStats()
+	if len(stats) != 3 {
+		t.Fatalf("Expected 3 exemplars to be kept, got %d", len(stats))
+	}
+	for _, stat := range stats {
+		if blocked := stat.GetTotalSelectBlockedTime(); blocked < (goroutines-3)*time.Microsecond {
+			t.Errorf("Expected only the most blocked goroutines as exemplars, got one blocked %v", blocked)
+		}
+	}
+
+	group := gm.GetAllGroupStats()["requests"]
+	if group == nil {
+		t.Fatal("Expected the evicted goroutines to be rolled up into their group")
+	}
+	if group.GetGoroutineCount() != goroutines-3 {
+		t.Errorf("Expected %d rolled up goroutines, got %d", goroutines-3, group.GetGoroutineCount())
+	}
+	if hits := group.GetSelectCaseStats("received").GetCaseHits(); hits != goroutines-3 {
+		t.Errorf("Expected %d rolled up hits, got %d", goroutines-3, hits)
+	}
+
+	jsonStats := gm.Snapshot().JSON("test")
+	if len(jsonStats.Goroutines) != 3 || jsonStats.Groups["requests"].Goroutines != goroutines-3 {
+		t.Errorf("Expected exemplars and groups in the JSON report, got %d goroutines and %+v",
+			len(jsonStats.Goroutines), jsonStats.Groups)
+	}
+
+	gm.SnapshotAndReset()
+	if len(gm.GetAllGroupStats()) != 0 {
+		t.Error("Expected groups to be cleared by SnapshotAndReset")
+	}
+}
+
+func TestRetentionKeepsRunningGoroutines(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.MaxFinishedGoroutines = 1
+
+	id := gm.TrackGoroutineStart()
+	gm.TrackSelectCase("case1", time.Millisecond, id)
+
+	for range 2 {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			finished := gm.TrackGoroutineStart()
+			gm.TrackGoroutineEnd(finished)
+		}()
+		<-done
+	}
+
+	if gm.GetGoroutineStats(id) == nil {
+		t.Error("Expected running goroutines never to be rolled up")
+	}
+	if group := gm.GetAllGroupStats()[tracker.DefaultGroup]; group == nil || group.GetGoroutineCount() != 1 {
+		t.Errorf("Expected one goroutine rolled up into %s, got %+v", tracker.DefaultGroup, group)
+	}
+}
@@ -0,0 +1,154 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/sharedtypes"
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+func TestSamplingEvery(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.Sampling.Every = 10
+	gm.SetCaseSampling("cold", tracker.Sampling{})
+	id := gm.TrackGoroutineStart()
+
+	for range 1000 {
+		gm.TrackSelectCase("hot", time.Millisecond, id)
+	}
+	for range 10 {
+		gm.TrackSelectCase("cold", time.Millisecond, id)
+	}
+
+	stats := gm.GetGoroutineStats(id)
+	hot := stats.GetSelectCaseStats("hot")
+	if hot.GetCaseHits() != 1000 {
+		t.Errorf("Expected 1000 scaled hits, got %d", hot.GetCaseHits())
+	}
+	if hot.GetSampledHits() != 100 {
+		t.Errorf("Expected 100 sampled hits, got %d", hot.GetSampledHits())
+	}
+	if hot.GetCaseTime() != time.Second {
+		t.Errorf("Expected scaled blocked time %v, got %v", time.Second, hot.GetCaseTime())
+	}
+
+	cold := stats.GetSelectCaseStats("cold")
+	if cold.GetSampledHits() != 10 || cold.GetSampleRate() != 1 {
+		t.Errorf("Expected every hit of the unsampled case to be measured, got %d", cold.GetSampledHits())
+	}
+
+	jsonStats := tracker.NewJSONStats(gm.GetAllStats(), "test")
+	for _, goroutine := range jsonStats.Goroutines {
+		if rate := goroutine.SelectCaseStats["hot"].SampleRate; rate != 0.1 {
+			t.Errorf("Expected sample rate 0.1 in JSON, got %v", rate)
+		}
+		if rate := goroutine.SelectCaseStats["cold"].SampleRate; rate != 0 {
+			t.Errorf("Expected no sample rate for unsampled case, got %v", rate)
+		}
+	}
+}
+
+func TestSamplingInterval(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.SetCaseSampling("case1", tracker.Sampling{Interval: 50 * time.Millisecond})
+	id := gm.TrackGoroutineStart()
+
+	for range 6 {
+		gm.TrackSelectCase("case1", time.Millisecond, id)
+	}
+	time.Sleep(60 * time.Millisecond)
+	gm.TrackSelectCase("case1", time.Millisecond, id)
+
+	caseStats := gm.GetGoroutineStats(id).GetSelectCaseStats("case1")
+	if caseStats.GetSampledHits() != 2 {
+		t.Errorf("Expected 2 sampled hits, got %d", caseStats.GetSampledHits())
+	}
+	if caseStats.GetCaseHits() != 7 {
+		t.Errorf("Expected 7 scaled hits, got %d", caseStats.GetCaseHits())
+	}
+}
+
+func TestAggregateSampledCases(t *testing.T) {
+	aggregated := tracker.AggregateCaseStats([]*sharedtypes.CaseJSON{
+		{CaseName: "case1", Hits: 1000, SampledHits: 100, SampleRate: 0.1},
+		{CaseName: "case1", Hits: 100},
+	})
+
+	stat := aggregated["case1"]
+	if stat.Hits != 1100 || stat.SampledHits != 200 {
+		t.Errorf("Expected 1100 hits with 200 sampled, got %d with %d", stat.Hits, stat.SampledHits)
+	}
+	if stat.SampleRate != 200.0/1100.0 {
+		t.Errorf("Expected sample rate %v, got %v", 200.0/1100.0, stat.SampleRate)
+	}
+}
+
+func TestSamplingRegisteredCaseHasOwnSampler(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.Sampling.Every = 2
+	registered := gm.RegisterCase("registered")
+	id := gm.TrackGoroutineStart()
+
+	// alternating hits would starve one of the names if they shared a sampler
+	for range 4 {
+		gm.TrackCase(registered, time.Millisecond, id)
+		gm.TrackSelectCase("unregistered", time.Millisecond, id)
+	}
+
+	stats := gm.GetGoroutineStats(id)
+	if hits := stats.GetSelectCaseStats("registered").GetCaseHits(); hits != 4 {
+		t.Errorf("Expected 4 scaled hits for the registered case, got %d", hits)
+	}
+	if hits := stats.GetSelectCaseStats("unregistered").GetCaseHits(); hits != 4 {
+		t.Errorf("Expected 4 scaled hits for the unregistered case, got %d", hits)
+	}
+}
+
+func TestSamplingUnregisteredCasesHaveOwnSamplers(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.Sampling.Every = 2
+	id := gm.TrackGoroutineStart()
+
+	for range 1000 {
+		gm.TrackSelectCase("a", time.Millisecond, id)
+		gm.TrackSelectCase("b", time.Millisecond, id)
+	}
+
+	stats := gm.GetGoroutineStats(id)
+	for _, name := range []string{"a", "b"} {
+		caseStats := stats.GetSelectCaseStats(name)
+		if caseStats.GetSampledHits() != 500 {
+			t.Errorf("Expected 500 sampled hits for %q, got %d", name, caseStats.GetSampledHits())
+		}
+		if caseStats.GetCaseHits() != 1000 {
+			t.Errorf("Expected 1000 scaled hits for %q, got %d", name, caseStats.GetCaseHits())
+		}
+	}
+}
+
+func TestSamplingSamplersBoundedByMaxCaseNames(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.Sampling.Every = 2
+	gm.MaxCaseNames = 1
+	id := gm.TrackGoroutineStart()
+
+	gm.TrackSelectCase("a", time.Millisecond, id)
+	gm.TrackSelectCase("a", time.Millisecond, id)
+	// "b" and "c" share the sampler of __other__ once "a" fills the map
+	for range 10 {
+		gm.TrackSelectCase("b", time.Millisecond, id)
+		gm.TrackSelectCase("c", time.Millisecond, id)
+	}
+
+	stats := gm.GetGoroutineStats(id)
+	if hits := stats.GetSelectCaseStats("a").GetSampledHits(); hits != 1 {
+		t.Errorf("Expected 1 sampled hit for the first case, got %d", hits)
+	}
+	other := stats.GetSelectCaseStats(tracker.OtherCaseName).GetSampledHits()
+	if other != 10 {
+		t.Errorf("Expected 10 sampled hits folded into %s, got %d", tracker.OtherCaseName, other)
+	}
+}
@@ -0,0 +1,163 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+	"github.com/AlexsanderHamir/IdleSpy/visualization"
+)
+
+func TestShutdownLatency(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ids := make(chan tracker.GoroutineId, 4)
+	release := make(chan struct{})
+	for _, delay := range []time.Duration{0, 5 * time.Millisecond, 30 * time.Millisecond} {
+		go func() {
+			id := gm.TrackGoroutineStartInGroup("workers")
+			defer gm.TrackGoroutineEnd(id)
+			gm.WatchContext(ctx, id)
+			ids <- id
+			<-ctx.Done()
+			time.Sleep(delay)
+		}()
+	}
+
+	go func() {
+		id := gm.TrackGoroutineStartInGroup("workers")
+		defer gm.TrackGoroutineEnd(id)
+		gm.WatchContext(ctx, id)
+		ids <- id
+		<-release
+	}()
+
+	go func() {
+		id := gm.TrackGoroutineStartInGroup("idle")
+		defer gm.TrackGoroutineEnd(id)
+		ids <- id
+		<-ctx.Done()
+	}()
+
+	for range 5 {
+		<-ids
+	}
+	cancel()
+	time.Sleep(100 * time.Millisecond)
+
+	shutdowns := gm.Snapshot().Shutdowns()
+	if _, exists := shutdowns["idle"]; exists {
+		t.Errorf("Expected goroutines without a watched context to be left out")
+	}
+	workers := shutdowns["workers"]
+	if workers == nil || workers.Exits != 3 || workers.Pending != 1 {
+		t.Fatalf("Expected 3 exits and 1 pending goroutine, got %+v", workers)
+	}
+	if workers.MaxLatency < 30*time.Millisecond || workers.GetPercentile(50) >= 30*time.Millisecond {
+		t.Errorf("Expected a max of at least 30ms and a lower median, got max %v and p50 %v", workers.MaxLatency, workers.GetPercentile(50))
+	}
+	if !workers.Slowest[0].Pending || workers.Slowest[0].Latency < workers.MaxLatency {
+		t.Errorf("Expected the goroutine still running to be the slowest, got %+v", workers.Slowest)
+	}
+
+	close(release)
+	gm.Wg.Wait()
+
+	workers = gm.Snapshot().Shutdowns()["workers"]
+	if workers.Exits != 4 || workers.Pending != 0 || workers.MaxLatency < 100*time.Millisecond {
+		t.Errorf("Expected 4 exits with the released goroutine the slowest, got %+v", workers)
+	}
+	for _, exit := range workers.Slowest {
+		if stats := gm.GetGoroutineStats(exit.GoroutineId); stats.GetShutdownLatency() != exit.Latency {
+			t.Errorf("Expected goroutine %d's latency %v to be reported, got %v", exit.GoroutineId, stats.GetShutdownLatency(), exit.Latency)
+		}
+	}
+}
+
+func TestShutdownLatencyRolledUp(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.MaxFinishedGoroutines = 1
+
+	for range 3 {
+		ctx, cancel := context.WithCancel(context.Background())
+		runTracked(gm, "workers", func(id tracker.GoroutineId) {
+			gm.WatchContext(ctx, id)
+			cancel()
+			<-ctx.Done()
+			time.Sleep(time.Millisecond)
+		})
+	}
+
+	group := gm.GetAllGroupStats()["workers"]
+	if group == nil || group.Shutdown == nil || group.Shutdown.Exits != 2 {
+		t.Fatalf("Expected 2 rolled up exits, got %+v", group)
+	}
+
+	data, err := json.Marshal(gm.Snapshot().JSON("test"))
+	if err != nil {
+		t.Fatalf("Error marshaling stats: %v", err)
+	}
+	groups, err := visualization.ParseJSONToShutdowns(data)
+	if err != nil {
+		t.Fatalf("Error parsing shutdowns: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Group != "workers" || groups[0].Exits != 3 || len(groups[0].Slowest) != 3 {
+		t.Fatalf("Expected the kept and rolled up exits in one group, got %+v", groups)
+	}
+	if groups[0].Percentile99 < time.Millisecond || groups[0].MaxLatency < groups[0].Percentile50 {
+		t.Errorf("Expected percentiles of at least 1ms, got %+v", groups[0])
+	}
+}
+
+// blockingWriter reports its first write on started and blocks every write
+// until release is closed
+type blockingWriter struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	select {
+	case w.started <- struct{}{}:
+	default:
+	}
+	<-w.release
+	return len(p), nil
+}
+
+func TestShutdownLatencyWhileManagerBusy(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.MaxCaseNames = 1
+	gm.SetCaseKind("first", tracker.KindReceive)
+
+	writer := &blockingWriter{started: make(chan struct{}, 1), release: make(chan struct{})}
+	prev := log.Writer()
+	log.SetOutput(writer)
+	defer log.SetOutput(prev)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	id := goTracked(gm, "", func(id tracker.GoroutineId) {
+		gm.WatchContext(ctx, id)
+		<-ctx.Done()
+		time.Sleep(5 * time.Millisecond)
+	})
+
+	// the ignored kind is logged while the manager's lock is held, so both
+	// the cancellation and the goroutine's end wait for it
+	go gm.SetCaseKind("second", tracker.KindSend)
+	<-writer.started
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	close(writer.release)
+	gm.Wg.Wait()
+
+	if latency := gm.GetGoroutineStats(id).GetShutdownLatency(); latency < 5*time.Millisecond {
+		t.Errorf("Expected a shutdown latency of at least 5ms, got %v", latency)
+	}
+}
@@ -0,0 +1,81 @@
+//go:build unix && !idlespy_off
+
+package test
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+func TestFlushOnDumpSignal(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	gm := tracker.NewGoroutineManager()
+	gm.FileType = "json"
+	gm.Action = tracker.Save
+	gm.HandleSignals()
+
+	id := gm.TrackGoroutineStart()
+	gm.TrackSelectCase("case1", time.Millisecond, id)
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("Error sending signal: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !tracker.FileExists(".internal.json") {
+		if time.Now().After(deadline) {
+			t.Fatal("Stats were not flushed after SIGUSR1")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// the manager keeps tracking after an on-demand dump
+	gm.TrackSelectCase("case1", time.Millisecond, id)
+	gm.TrackGoroutineEnd(id)
+	if err := gm.Done(); err != nil {
+		t.Errorf("Error saving stats: %v", err)
+	}
+	if hits := gm.GetGoroutineStats(id).GetSelectCaseStats("case1").GetCaseHits(); hits != 2 {
+		t.Errorf("Expected 2 case hits, got %d", hits)
+	}
+}
+
+func TestRecoverAndFlush(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	gm := tracker.NewGoroutineManager()
+	gm.FileType = "json"
+	gm.Action = tracker.Save
+
+	var id tracker.GoroutineId
+	recovered := make(chan any)
+	go func() {
+		defer func() {
+			recovered <- recover()
+		}()
+
+		id = gm.TrackGoroutineStart()
+		defer gm.RecoverAndFlush(id)
+
+		gm.TrackSelectCase("case1", time.Millisecond, id)
+		panic("boom")
+	}()
+
+	if r := <-recovered; r != "boom" {
+		t.Errorf("Expected panic to be resumed, got %v", r)
+	}
+
+	if !tracker.FileExists(".internal.json") {
+		t.Error("Stats were not flushed on panic")
+	}
+	if gm.GetGoroutineStats(id).EndTime.IsZero() {
+		t.Error("End time was not set for panicking goroutine")
+	}
+
+	// the goroutine's WaitGroup slot was released
+	gm.Wg.Wait()
+}
@@ -0,0 +1,126 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/test/fixtures/sitea"
+	"github.com/AlexsanderHamir/IdleSpy/test/fixtures/siteb"
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+	"github.com/AlexsanderHamir/IdleSpy/visualization"
+)
+
+func TestSelectSites(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	id := gm.TrackGoroutineStart()
+
+	for range 3 {
+		gm.TrackSiteCase("consumer", "received", time.Millisecond, id)
+	}
+	gm.TrackSiteCase("consumer", "ctx_done", time.Millisecond, id)
+	// the same case name in another select stays apart
+	gm.TrackSiteCase("producer", "ctx_done", 2*time.Millisecond, id)
+	gm.TrackSiteCase("producer", "sent", 2*time.Millisecond, id)
+
+	sites := gm.Snapshot().Sites()
+	consumer, producer := sites["consumer"], sites["producer"]
+	if consumer == nil || producer == nil {
+		t.Fatalf("Expected both sites to be reported, got %v", sites)
+	}
+	if consumer.Hits != 4 || consumer.Wins["ctx_done"] != 1 || producer.Wins["ctx_done"] != 1 {
+		t.Errorf("Expected ctx_done to be counted per site, got %v and %v", consumer.Wins, producer.Wins)
+	}
+	if rate := consumer.GetWinRate("received"); rate != 0.75 {
+		t.Errorf("Expected received to win 75%% of the runs, got %v", rate)
+	}
+	if producer.BlockedTime != 4*time.Millisecond {
+		t.Errorf("Expected 4ms blocked time for the producer, got %v", producer.BlockedTime)
+	}
+
+	if fairness := producer.GetFairness(); math.Abs(fairness-1) > 1e-9 {
+		t.Errorf("Expected an even distribution to be fully fair, got %v", fairness)
+	}
+	if fairness := consumer.GetFairness(); fairness <= 0 || fairness >= 1 {
+		t.Errorf("Expected a skewed distribution to be partially fair, got %v", fairness)
+	}
+
+	data, err := json.Marshal(gm.Snapshot().JSON("test"))
+	if err != nil {
+		t.Fatalf("Error marshaling stats: %v", err)
+	}
+	parsed, err := visualization.ParseJSONToSites(data)
+	if err != nil {
+		t.Fatalf("Error parsing sites: %v", err)
+	}
+	if len(parsed) != 2 || parsed[0].Site != "consumer" || parsed[0].Cases[0].CaseName != "received" {
+		t.Errorf("Expected sites sorted by name with the dominant case first, got %+v", parsed)
+	}
+}
+
+func TestSiteDefaultsToCaller(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	id := gm.TrackGoroutineStart()
+
+	for _, won := range []string{"received", "ctx_done"} {
+		gm.TrackSiteCase("", won, time.Millisecond, id)
+	}
+	// a second select in the same function is a different site
+	gm.TrackSiteCase("", "received", time.Millisecond, id)
+
+	sites := gm.Snapshot().Sites()
+	if len(sites) != 2 {
+		t.Fatalf("Expected two sites, got %v", sites)
+	}
+	grouped := false
+	for site, stats := range sites {
+		if !strings.Contains(site, "/test/sites_test.go:") || !strings.HasPrefix(stats.Name, "sites_test.go:") {
+			t.Errorf("Expected the site to be keyed by the call's file path and shown with its file name, got %q and %q", site, stats.Name)
+		}
+		if stats.Wins["received"] == 1 && stats.Wins["ctx_done"] == 1 {
+			grouped = true
+		}
+	}
+	if !grouped {
+		t.Errorf("Expected both cases of the first select under one site, got %v", sites)
+	}
+}
+
+func TestSitesOfSameFileNameInDifferentPackages(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	id := gm.TrackGoroutineStart()
+
+	// both fixtures record from site.go at the same line
+	sitea.Record(gm, "received", id)
+	sitea.Record(gm, "received", id)
+	siteb.Record(gm, "ctx_done", id)
+
+	sites := gm.Snapshot().Sites()
+	if len(sites) != 2 {
+		t.Fatalf("Expected the two packages to be separate sites, got %v", sites)
+	}
+	for key, site := range sites {
+		if !strings.HasPrefix(site.Name, "site.go:") {
+			t.Errorf("Expected site %q to be shown with its file name, got %q", key, site.Name)
+		}
+		if len(site.Wins) != 1 || site.GetFairness() != 0 {
+			t.Errorf("Expected a single case per site, got %v", site.Wins)
+		}
+	}
+
+	data, err := json.Marshal(gm.Snapshot().JSON("test"))
+	if err != nil {
+		t.Fatalf("Error marshaling stats: %v", err)
+	}
+	parsed, err := visualization.ParseJSONToSites(data)
+	if err != nil {
+		t.Fatalf("Error parsing sites: %v", err)
+	}
+	if len(parsed) != 2 || parsed[0].Name != parsed[1].Name || parsed[0].Site == parsed[1].Site {
+		t.Errorf("Expected two sites with the same short name and different keys, got %+v", parsed)
+	}
+}
@@ -0,0 +1,90 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+func TestSnapshotAndReset(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	id := gm.TrackGoroutineStart()
+
+	gm.TrackSelectCase("case1", 10*time.Millisecond, id)
+	gm.TrackSelectCase("case1", 20*time.Millisecond, id)
+
+	snap := gm.SnapshotAndReset()
+	caseStats := snap.Stats[id].GetSelectCaseStats("case1")
+	if caseStats == nil {
+		t.Fatal("Select stats not found in snapshot")
+	}
+	if caseStats.GetCaseHits() != 2 {
+		t.Errorf("Expected 2 case hits, got %d", caseStats.GetCaseHits())
+	}
+	if caseStats.GetCaseTime() != 30*time.Millisecond {
+		t.Errorf("Expected case time %v, got %v", 30*time.Millisecond, caseStats.GetCaseTime())
+	}
+
+	// counters start from zero after the reset
+	if stats := gm.GetGoroutineStats(id); len(stats.GetSelectStats()) != 0 {
+		t.Errorf("Expected no select stats after reset, got %d", len(stats.GetSelectStats()))
+	}
+
+	gm.TrackSelectCase("case1", 5*time.Millisecond, id)
+	next := gm.SnapshotAndReset()
+	if next.Since != snap.Taken {
+		t.Errorf("Expected interval to start at %v, got %v", snap.Taken, next.Since)
+	}
+	caseStats = next.Stats[id].GetSelectCaseStats("case1")
+	if caseStats.GetCaseHits() != 1 || caseStats.GetPercentile(99) != 5*time.Millisecond {
+		t.Errorf("Expected only the hit recorded after the reset, got %d hits", caseStats.GetCaseHits())
+	}
+	if next.Stats[id].GetGoroutineLifetime() > next.Taken.Sub(next.Since) {
+		t.Error("Lifetime exceeds the snapshot interval")
+	}
+
+	// finished goroutines are dropped by the reset
+	gm.TrackGoroutineEnd(id)
+	gm.SnapshotAndReset()
+	if gm.GetGoroutineStats(id) != nil {
+		t.Error("Finished goroutine was not dropped by the reset")
+	}
+}
+
+func TestSnapshotDelta(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	id := gm.TrackGoroutineStart()
+
+	gm.TrackSelectCase("case1", 10*time.Millisecond, id)
+	prev := gm.Snapshot()
+
+	gm.TrackSelectCase("case1", 30*time.Millisecond, id)
+	gm.TrackSelectCase("case2", 50*time.Millisecond, id)
+	curr := gm.Snapshot()
+
+	delta := tracker.Delta(prev, curr)
+	stats := delta.Stats[id]
+	if stats == nil {
+		t.Fatal("Goroutine not found in delta")
+	}
+
+	case1 := stats.GetSelectCaseStats("case1")
+	if case1.GetCaseHits() != 1 || case1.GetCaseTime() != 30*time.Millisecond {
+		t.Errorf("Expected 1 hit of %v, got %d hits of %v", 30*time.Millisecond, case1.GetCaseHits(), case1.GetCaseTime())
+	}
+	if case1.GetPercentile(50) != 30*time.Millisecond {
+		t.Errorf("Expected delta distribution to only hold new latencies, got p50 %v", case1.GetPercentile(50))
+	}
+
+	case2 := stats.GetSelectCaseStats("case2")
+	if case2 == nil || case2.GetCaseHits() != 1 {
+		t.Error("Expected case2 to appear in delta")
+	}
+
+	if total := stats.GetTotalSelectBlockedTime(); total != 80*time.Millisecond {
+		t.Errorf("Expected total select time %v, got %v", 80*time.Millisecond, total)
+	}
+}
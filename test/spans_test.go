@@ -0,0 +1,112 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+func TestWorkSpans(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	id := gm.TrackGoroutineStart()
+
+	outer := gm.BeginSpan("request", id)
+	time.Sleep(10 * time.Millisecond)
+	inner := gm.BeginSpan("encode", id)
+	time.Sleep(10 * time.Millisecond)
+	inner.End()
+	// waiting inside a span is not work
+	gm.TrackSelectCase("reply", 5*time.Millisecond, id)
+	outer.End()
+	outer.End()
+
+	time.Sleep(10 * time.Millisecond)
+	gm.TrackGoroutineEnd(id)
+
+	stats := gm.GetGoroutineStats(id)
+	request := stats.GetSpanStats("request")
+	encode := stats.GetSpanStats("encode")
+	if request == nil || encode == nil {
+		t.Fatal("Expected both spans to be recorded")
+	}
+	if request.Count != 1 || encode.Count != 1 {
+		t.Errorf("Expected each span to end once, got %d and %d", request.Count, encode.Count)
+	}
+	if request.SelfTime != request.TotalTime-encode.TotalTime {
+		t.Errorf("Expected self time %v to exclude the nested span, got %v", request.TotalTime-encode.TotalTime, request.SelfTime)
+	}
+	if stats.SpanTime != request.TotalTime {
+		t.Errorf("Expected span time %v to only count the outermost span, got %v", request.TotalTime, stats.SpanTime)
+	}
+
+	if working := stats.GetWorkingTime(); working != request.TotalTime-5*time.Millisecond {
+		t.Errorf("Expected working time %v, got %v", request.TotalTime-5*time.Millisecond, working)
+	}
+	lifetime := stats.GetGoroutineLifetime()
+	if sum := stats.GetWorkingTime() + stats.GetTotalSelectBlockedTime() + stats.GetUnaccountedTime(); sum != lifetime {
+		t.Errorf("Expected the breakdown to add up to the lifetime %v, got %v", lifetime, sum)
+	}
+	if stats.GetUnaccountedTime() < 10*time.Millisecond {
+		t.Errorf("Expected the time after the span to be unaccounted, got %v", stats.GetUnaccountedTime())
+	}
+
+	goroutine := gm.Snapshot().JSON("test").Goroutines[jsonKey(id)]
+	if goroutine.WorkingTime != stats.GetWorkingTime() || goroutine.Spans["encode"].Count != 1 {
+		t.Errorf("Expected working time and spans in the JSON report, got %+v", goroutine)
+	}
+}
+
+func TestUnendedSpansEndWithTheirParent(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	id := gm.TrackGoroutineStart()
+
+	outer := gm.BeginSpan("outer", id)
+	gm.BeginSpan("forgotten", id)
+	outer.End()
+
+	stats := gm.GetGoroutineStats(id)
+	if stats.GetSpanStats("forgotten") == nil {
+		t.Error("Expected the nested span to end with its parent")
+	}
+
+	// handling recorded outside of spans is work
+	gm.TrackSelectCaseHandled("received", time.Millisecond, 3*time.Millisecond, id)
+	if working := stats.GetWorkingTime(); working < 3*time.Millisecond {
+		t.Errorf("Expected handling time to count as work, got %v", working)
+	}
+}
+
+func TestOpenSpansCountUntilSnapshotAndEnd(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	id := gm.TrackGoroutineStart()
+	gm.BeginSpan("work", id)
+	time.Sleep(10 * time.Millisecond)
+
+	snap := gm.SnapshotAndReset()
+	stats := snap.Stats[id]
+	work := stats.GetSpanStats("work")
+	if work == nil || work.Count != 0 {
+		t.Fatalf("Expected the open span in the snapshot without being counted as ended, got %+v", work)
+	}
+	if stats.SpanTime < 10*time.Millisecond || work.TotalTime != stats.SpanTime {
+		t.Errorf("Expected the open span to count up to the snapshot, got span time %v and total %v", stats.SpanTime, work.TotalTime)
+	}
+	if stats.GetWorkingTime() != stats.SpanTime {
+		t.Errorf("Expected working time %v from the open span, got %v", stats.SpanTime, stats.GetWorkingTime())
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	gm.TrackGoroutineEnd(id)
+
+	stats = gm.GetGoroutineStats(id)
+	work = stats.GetSpanStats("work")
+	if work == nil || work.Count != 1 {
+		t.Fatalf("Expected the open span to end with the goroutine, got %+v", work)
+	}
+	if want := stats.EndTime.Sub(snap.Taken); stats.SpanTime != want {
+		t.Errorf("Expected span time %v counted from the reset, got %v", want, stats.SpanTime)
+	}
+}
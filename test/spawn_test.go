@@ -0,0 +1,99 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+	"github.com/AlexsanderHamir/IdleSpy/visualization"
+)
+
+func TestSpawnSiteAndParent(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+
+	var parentId, childId tracker.GoroutineId
+	var line int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		parentId = gm.TrackGoroutineStart()
+		gm.TrackSelectCase("received", 2*time.Millisecond, parentId)
+
+		childDone := make(chan struct{})
+		_, _, line, _ = runtime.Caller(0)
+		go func() {
+			defer close(childDone)
+			childId = gm.TrackGoroutineStart()
+			gm.TrackSelectCase("received", time.Millisecond, childId)
+			gm.TrackGoroutineEnd(childId)
+		}()
+		<-childDone
+		gm.TrackGoroutineEnd(parentId)
+	}()
+	<-done
+
+	child := gm.GetGoroutineStats(childId)
+	if child.ParentId != parentId {
+		t.Errorf("Expected parent %d, got %d", parentId, child.ParentId)
+	}
+	if child.SpawnSite == nil || !strings.HasSuffix(child.SpawnSite.File, "spawn_test.go") || child.SpawnSite.Line != line+1 {
+		t.Errorf("Expected the child to be spawned at spawn_test.go:%d, got %s", line+1, child.SpawnSite)
+	}
+	if !strings.Contains(child.SpawnSite.Function, "TestSpawnSiteAndParent") {
+		t.Errorf("Expected the spawning function, got %q", child.SpawnSite.Function)
+	}
+
+	data, err := json.Marshal(gm.Snapshot().JSON("test"))
+	if err != nil {
+		t.Fatalf("Error marshaling stats: %v", err)
+	}
+	roots, err := visualization.ParseJSONToTree(data)
+	if err != nil {
+		t.Fatalf("Error parsing tree: %v", err)
+	}
+	if len(roots) != 1 || roots[0].ID != int(parentId) {
+		t.Fatalf("Expected goroutine %d as the only root, got %+v", parentId, roots)
+	}
+
+	root := roots[0]
+	if len(root.Children) != 1 || root.Children[0].ID != int(childId) {
+		t.Fatalf("Expected goroutine %d as the only child, got %+v", childId, root.Children)
+	}
+	if root.Goroutines != 2 || root.Blocked != 3*time.Millisecond {
+		t.Errorf("Expected the root to aggregate 2 goroutines blocked 3ms, got %d blocked %v", root.Goroutines, root.Blocked)
+	}
+	if root.Lifetime < root.Children[0].Lifetime {
+		t.Errorf("Expected the root lifetime %v to include the child's %v", root.Lifetime, root.Children[0].Lifetime)
+	}
+}
+
+func TestSpawnParentUnknownOnDeepStack(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+
+	var id tracker.GoroutineId
+	var deep func(depth int)
+	deep = func(depth int) {
+		if depth == 0 {
+			id = gm.TrackGoroutineStart()
+			return
+		}
+		deep(depth - 1)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		deep(200)
+	}()
+	<-done
+
+	stats := gm.GetGoroutineStats(id)
+	if stats.ParentId != tracker.UnknownParent || stats.SpawnSite != nil {
+		t.Errorf("Expected an unknown parent for a truncated stack, got %d at %s", stats.ParentId, stats.SpawnSite)
+	}
+}
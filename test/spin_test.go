@@ -0,0 +1,94 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+	"github.com/AlexsanderHamir/IdleSpy/visualization"
+)
+
+func TestSpinningGoroutineIsFlagged(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	spinner := pollInGroup(gm, "pollers", 500, 1)
+	blocker := pollInGroup(gm, "workers", 1, 20)
+
+	spin := gm.GetGoroutineStats(spinner).GetSpinStats()
+	if spin.DefaultHits != 500 || spin.CaseHits != 1 || spin.Ratio != 500 {
+		t.Errorf("Expected 500 default hits against 1 case hit, got %+v", spin)
+	}
+	if kind := gm.GetGoroutineStats(spinner).GetSelectCaseStats(tracker.DefaultCaseName).Kind; kind != tracker.KindDefault {
+		t.Errorf("Expected the default case to be kinded as default, got %q", kind)
+	}
+
+	snap := gm.Snapshot()
+	if !snap.IsSpinning(spin) {
+		t.Errorf("Expected the spinner to be flagged, got %+v", spin)
+	}
+	if spin := gm.GetGoroutineStats(blocker).GetSpinStats(); snap.IsSpinning(spin) {
+		t.Errorf("Expected the blocking goroutine not to be flagged, got %+v", spin)
+	}
+
+	data, err := json.Marshal(snap.JSON("test"))
+	if err != nil {
+		t.Fatalf("Error marshaling stats: %v", err)
+	}
+	var input visualization.JSONStats
+	if err := json.Unmarshal(data, &input); err != nil {
+		t.Fatalf("Error unmarshaling stats: %v", err)
+	}
+	spinning := visualization.SpinningGoroutines(input)
+	if len(spinning) != 1 || spinning[0] != "goroutine "+jsonKey(spinner) {
+		t.Errorf("Expected only goroutine %d to be spinning, got %v", spinner, spinning)
+	}
+}
+
+func TestSpinningGroupIsFlagged(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.MaxFinishedGoroutines = 1
+	pollInGroup(gm, "pollers", 500, 1)
+	pollInGroup(gm, "pollers", 500, 1)
+
+	group := gm.GetAllGroupStats()["pollers"]
+	if group == nil {
+		t.Fatalf("Expected a pollers group to be rolled up")
+	}
+	if spin := group.GetSpinStats(); spin.DefaultHits != 500 || !gm.Snapshot().IsSpinning(spin) {
+		t.Errorf("Expected the rolled up poller to be flagged, got %+v", spin)
+	}
+
+	groupJSON := gm.Snapshot().JSON("test").Groups["pollers"]
+	if groupJSON.Spin == nil || !groupJSON.Spin.Spinning {
+		t.Errorf("Expected the group to be flagged in the JSON report, got %+v", groupJSON.Spin)
+	}
+}
+
+func TestSpinThresholds(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.SpinRatio = 1000
+	id := pollInGroup(gm, "pollers", 500, 1)
+
+	goroutineJSON := gm.Snapshot().JSON("test").Goroutines[jsonKey(id)]
+	if goroutineJSON.Spin == nil || goroutineJSON.Spin.DefaultHits != 500 {
+		t.Fatalf("Expected the spin stats in the JSON report, got %+v", goroutineJSON.Spin)
+	}
+	if goroutineJSON.Spin.Spinning {
+		t.Errorf("Expected a ratio of 500 to stay below the threshold of 1000")
+	}
+}
+
+// pollInGroup runs a goroutine that takes the default branch defaults times
+// and a real case cases times, and waits for it to end
+func pollInGroup(gm *tracker.GoroutineManager, group string, defaults, cases int) tracker.GoroutineId {
+	return runTracked(gm, group, func(id tracker.GoroutineId) {
+		for range defaults {
+			gm.TrackSelectCase(tracker.DefaultCaseName, 0, id)
+		}
+		for range cases {
+			gm.TrackSelectCase("item_received", time.Millisecond, id)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+	"github.com/AlexsanderHamir/IdleSpy/visualization"
+)
+
+func TestSnapshotStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stream.ndjson")
+
+	gm := tracker.NewGoroutineManager()
+	gm.Action = tracker.None
+	if err := gm.StartStream(path, 10*time.Millisecond); err != nil {
+		t.Fatalf("Error starting stream: %v", err)
+	}
+	if err := gm.StartStream(path, 10*time.Millisecond); err == nil {
+		t.Error("Expected error when starting a second stream")
+	}
+
+	goTracked(gm, "", func(id tracker.GoroutineId) {
+		gm.TrackSelectCase("case1", 5*time.Millisecond, id)
+		time.Sleep(50 * time.Millisecond)
+		gm.TrackSelectCase("case1", 5*time.Millisecond, id)
+	})
+
+	if err := gm.Done(); err != nil {
+		t.Fatalf("Error finishing manager: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading stream file: %v", err)
+	}
+
+	// a partially written line left behind by a crash must not break parsing
+	data = append(data, []byte(`{"time":"2024-01-01T00:00:00Z","goroutines":{"1":`)...)
+
+	points, err := visualization.ParseStreamToTimeline(data)
+	if err != nil {
+		t.Fatalf("Error parsing stream: %v", err)
+	}
+	if len(points) < 2 {
+		t.Fatalf("Expected at least 2 stream records, got %d", len(points))
+	}
+
+	last := points[len(points)-1]
+	if last.Hits != 2 {
+		t.Errorf("Expected 2 hits in final record, got %d", last.Hits)
+	}
+	if last.BlockedTime != 10*time.Millisecond {
+		t.Errorf("Expected %v blocked in final record, got %v", 10*time.Millisecond, last.BlockedTime)
+	}
+
+	var hits int64
+	for _, p := range points {
+		hits += p.HitsDelta
+	}
+	if hits != last.Hits {
+		t.Errorf("Expected hit deltas to add up to %d, got %d", last.Hits, hits)
+	}
+}
+
+func TestSnapshotStreamAcrossRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stream.ndjson")
+
+	run := func(hits int) {
+		gm := tracker.NewGoroutineManager()
+		gm.Action = tracker.None
+		if err := gm.StartStream(path, time.Hour); err != nil {
+			t.Fatalf("Error starting stream: %v", err)
+		}
+
+		goTracked(gm, "", func(id tracker.GoroutineId) {
+			for range hits {
+				gm.TrackSelectCase("case1", time.Millisecond, id)
+			}
+		})
+
+		if err := gm.Done(); err != nil {
+			t.Fatalf("Error finishing manager: %v", err)
+		}
+	}
+
+	run(5)
+
+	// the first run crashed in the middle of a line
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("Error opening stream file: %v", err)
+	}
+	f.WriteString(`{"time":"2024-01-01T00:00:00Z","goroutines":{"1":`)
+	f.Close()
+
+	run(2)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading stream file: %v", err)
+	}
+
+	points, err := visualization.ParseStreamToTimeline(data)
+	if err != nil {
+		t.Fatalf("Error parsing stream: %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("Expected 2 stream records, got %d", len(points))
+	}
+
+	second := points[1]
+	if !second.RunStart {
+		t.Error("Expected the second run's first record to start a run")
+	}
+	if second.HitsDelta != 2 {
+		t.Errorf("Expected 2 hits in the second run, got %d", second.HitsDelta)
+	}
+	for _, p := range points {
+		if p.HitsDelta < 0 || p.BlockedDelta < 0 {
+			t.Errorf("Expected non-negative deltas, got %d hits and %v blocked", p.HitsDelta, p.BlockedDelta)
+		}
+	}
+}
+
+func TestEnvStreamInOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(t.TempDir())
+	t.Setenv(tracker.EnvEnabled, "true")
+	t.Setenv(tracker.EnvAction, "none")
+	t.Setenv(tracker.EnvOutputDir, dir)
+	t.Setenv(tracker.EnvStreamInterval, "1h")
+
+	gm := tracker.NewGoroutineManager()
+	runTracked(gm, "", func(id tracker.GoroutineId) {
+		gm.TrackSelectCase("case1", time.Millisecond, id)
+	})
+	if err := gm.Done(); err != nil {
+		t.Fatalf("Error finishing manager: %v", err)
+	}
+
+	if path := tracker.StreamPath(); path != filepath.Join(dir, tracker.DefaultStreamPath) || !tracker.FileExists(path) {
+		t.Fatalf("Expected the stream under the output directory, got %q", path)
+	}
+	// the CLI finds the stream although the working directory has none
+	if err := visualization.GenerateTimeline(); err != nil {
+		t.Errorf("Error generating timeline: %v", err)
+	}
+}
@@ -1,3 +1,5 @@
+//go:build !idlespy_off
+
 package test
 
 import (
@@ -10,8 +12,6 @@ import (
 
 func TestTrackGoroutineStartAndEnd(t *testing.T) {
 	gm := tracker.NewGoroutineManager()
-	gm.Wg.Add(1)
-
 	// Test tracking in main goroutine
 	id := gm.TrackGoroutineStart()
 	if id <= 0 {
@@ -89,13 +89,9 @@ func TestConcurrentTracking(t *testing.T) {
 
 	gm.FileType = "json"
 	gm.Action = tracker.Save
-	gm.Wg.Add(goroutineCount)
 
 	for range goroutineCount {
-		go func() {
-			id := gm.TrackGoroutineStart()
-			defer gm.TrackGoroutineEnd(id)
-
+		goTracked(gm, "", func(id tracker.GoroutineId) {
 			gm.TrackSelectCase("case1", latency1, id)
 			gm.TrackSelectCase("case2", latency2, id)
 
@@ -103,7 +99,7 @@ func TestConcurrentTracking(t *testing.T) {
 			if stats == nil {
 				t.Error("Stats not found for concurrent goroutine")
 			}
-		}()
+		})
 	}
 
 	err := gm.Done()
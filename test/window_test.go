@@ -0,0 +1,77 @@
+//go:build !idlespy_off
+
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+func TestRollingWindows(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.Windows = tracker.DefaultWindows
+	id := gm.TrackGoroutineStart()
+
+	gm.TrackSelectCase("case1", 10*time.Millisecond, id)
+	gm.TrackSelectCase("case1", 10*time.Millisecond, id)
+	gm.TrackSelectCase("case1", 100*time.Millisecond, id)
+
+	caseStats := gm.GetGoroutineStats(id).GetSelectCaseStats("case1")
+	window := caseStats.GetWindowStats(time.Minute)
+	if window.Hits != 3 {
+		t.Errorf("Expected 3 hits in window, got %d", window.Hits)
+	}
+	if window.BlockedTime != 120*time.Millisecond {
+		t.Errorf("Expected %v blocked in window, got %v", 120*time.Millisecond, window.BlockedTime)
+	}
+
+	// percentiles are approximated to power-of-two buckets
+	for _, p := range []float64{50, 99} {
+		exact := caseStats.GetPercentile(p)
+		approx := window.Percentile50
+		if p == 99 {
+			approx = window.Percentile99
+		}
+		if approx < exact/2 || approx > exact*2 {
+			t.Errorf("Expected p%.0f close to %v, got %v", p, exact, approx)
+		}
+	}
+
+	snap := gm.Snapshot()
+	if windows := snap.Stats[id].GetSelectCaseStats("case1").GetWindows(); len(windows) != len(tracker.DefaultWindows) {
+		t.Errorf("Expected %d windows in snapshot, got %d", len(tracker.DefaultWindows), len(windows))
+	}
+
+	// the snapshot keeps its own copy of the slots
+	gm.TrackSelectCase("case1", 10*time.Millisecond, id)
+	if hits := snap.Stats[id].GetSelectCaseStats("case1").GetWindowStats(time.Minute).Hits; hits != 3 {
+		t.Errorf("Expected the snapshot's window to keep 3 hits, got %d", hits)
+	}
+
+	jsonStats := tracker.NewJSONStats(snap.Stats, "test")
+	for _, goroutine := range jsonStats.Goroutines {
+		if _, ok := goroutine.SelectCaseStats["case1"].Windows["5m"]; !ok {
+			t.Error("Expected 5m window in JSON output")
+		}
+	}
+}
+
+func TestRollingWindowExpires(t *testing.T) {
+	gm := tracker.NewGoroutineManager()
+	gm.Windows = []time.Duration{60 * time.Millisecond}
+	id := gm.TrackGoroutineStart()
+
+	gm.TrackSelectCase("case1", time.Millisecond, id)
+	time.Sleep(100 * time.Millisecond)
+	gm.TrackSelectCase("case1", time.Millisecond, id)
+
+	caseStats := gm.GetGoroutineStats(id).GetSelectCaseStats("case1")
+	if hits := caseStats.GetWindowStats(60 * time.Millisecond).Hits; hits != 1 {
+		t.Errorf("Expected old hits to leave the window, got %d hits", hits)
+	}
+	if caseStats.GetCaseHits() != 2 {
+		t.Errorf("Expected lifetime totals to keep all hits, got %d", caseStats.GetCaseHits())
+	}
+}
@@ -0,0 +1,103 @@
+//go:build !idlespy_off
+
+package tracker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Add adds delta to the WaitGroup counter
+func (wg *WaitGroup) Add(delta int) {
+	wg.wg.Add(delta)
+}
+
+// Done decrements the WaitGroup counter by one
+func (wg *WaitGroup) Done() {
+	wg.wg.Done()
+}
+
+// Wait blocks until the WaitGroup counter is zero, recording how long it blocked
+func (wg *WaitGroup) Wait() {
+	if wg.Manager == nil || !wg.Manager.Enabled {
+		wg.wg.Wait()
+		return
+	}
+
+	id := wg.Manager.callerID()
+	start := time.Now()
+	wg.wg.Wait()
+	wg.Manager.trackBlocking(id, wg.Name+WaitCaseSuffix, time.Since(start))
+}
+
+// NewCond creates a condition variable with the locker l whose waits are
+// recorded under name
+func NewCond(gm *GoroutineManager, name string, l sync.Locker) *Cond {
+	return &Cond{L: l, gm: gm, name: name, cond: sync.NewCond(l)}
+}
+
+// Wait unlocks c.L, waits to be woken by Signal or Broadcast and locks c.L
+// again before returning, recording how long it blocked
+func (c *Cond) Wait() {
+	if c.gm == nil || !c.gm.Enabled {
+		c.cond.Wait()
+		return
+	}
+
+	id := c.gm.callerID()
+	start := time.Now()
+	c.cond.Wait()
+	c.gm.trackBlocking(id, c.name+WaitCaseSuffix, time.Since(start))
+}
+
+// Signal wakes one goroutine waiting on c, if there is any
+func (c *Cond) Signal() {
+	c.cond.Signal()
+}
+
+// Broadcast wakes all goroutines waiting on c
+func (c *Cond) Broadcast() {
+	c.cond.Broadcast()
+}
+
+// NewSemaphore creates a weighted semaphore of size units whose waits are
+// recorded under name
+func NewSemaphore(gm *GoroutineManager, name string, size int64) *Semaphore {
+	return &Semaphore{gm: gm, name: name, size: size}
+}
+
+// Acquire takes n units, blocking until they are available or ctx is done,
+// and records how long it blocked. On failure it returns ctx.Err() and takes
+// nothing.
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	if s.gm == nil || !s.gm.Enabled {
+		return s.acquire(ctx, n)
+	}
+
+	id := s.gm.callerID()
+	start := time.Now()
+	err := s.acquire(ctx, n)
+	s.gm.trackBlocking(id, s.name+AcquireCaseSuffix, time.Since(start))
+	return err
+}
+
+// TryAcquire takes n units without blocking and reports whether it succeeded
+func (s *Semaphore) TryAcquire(n int64) bool {
+	return s.tryAcquire(n)
+}
+
+// Release gives back n units
+func (s *Semaphore) Release(n int64) {
+	s.release(n)
+}
+
+// trackBlocking records a wait on a sync primitive as a hit of caseName on
+// the goroutine id that waited, like the waits of a Chan or Mutex, unless id
+// is zero
+func (gm *GoroutineManager) trackBlocking(id GoroutineId, caseName string, wait time.Duration) {
+	entered := gm.overheadStart()
+	if id != 0 {
+		gm.attribute(id, caseName, nil, wait, 0, entered)
+	}
+}
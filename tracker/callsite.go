@@ -0,0 +1,57 @@
+//go:build !idlespy_off
+
+package tracker
+
+import (
+	"runtime"
+	"sync"
+)
+
+// maxCallSites bounds the call site cache, locations past it are looked up on
+// every hit
+const maxCallSites = 4096
+
+// callSites caches the call site of each program counter, so the symbol
+// lookup happens once per location
+var (
+	callSitesMu sync.RWMutex
+	callSites   = make(map[uintptr]*CallSite)
+)
+
+// callSite returns the location of the code that called the tracking method,
+// or nil when call sites are not captured. It must be called directly from
+// the exported tracking method.
+func (gm *GoroutineManager) callSite(caseName string) *CallSite {
+	if !gm.Enabled || (!gm.CaptureCallSites && caseName != "") {
+		return nil
+	}
+	return captureCallSite(2)
+}
+
+// captureCallSite returns the location skip frames above its caller
+func captureCallSite(skip int) *CallSite {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return nil
+	}
+	callSitesMu.RLock()
+	at, ok := callSites[pcs[0]]
+	callSitesMu.RUnlock()
+	if ok {
+		return at
+	}
+
+	// a separate slice keeps pcs on the stack on the cached path
+	frame, _ := runtime.CallersFrames([]uintptr{pcs[0]}).Next()
+	at = &CallSite{
+		Function: frame.Function,
+		File:     frame.File,
+		Line:     frame.Line,
+	}
+	callSitesMu.Lock()
+	if len(callSites) < maxCallSites {
+		callSites[pcs[0]] = at
+	}
+	callSitesMu.Unlock()
+	return at
+}
@@ -0,0 +1,67 @@
+//go:build !idlespy_off
+
+package tracker
+
+import "hash/maphash"
+
+// maxDroppedNames bounds the memory used to count dropped case names
+const maxDroppedNames = 1 << 16
+
+// admitCaseLocked returns the name hits of caseName must be recorded under,
+// folding names over MaxCaseNamesPerGoroutine or MaxCaseNames into
+// OtherCaseName. Caller must hold gm.mu.
+func (gm *GoroutineManager) admitCaseLocked(stats *GoroutineStats, caseName string) string {
+	if _, exists := stats.SelectStats[caseName]; exists || caseName == OtherCaseName {
+		return caseName
+	}
+
+	if gm.MaxCaseNamesPerGoroutine > 0 {
+		names := len(stats.SelectStats)
+		if _, exists := stats.SelectStats[OtherCaseName]; exists {
+			names--
+		}
+		if names >= gm.MaxCaseNamesPerGoroutine {
+			gm.dropCaseNameLocked(caseName)
+			return OtherCaseName
+		}
+	}
+
+	if gm.MaxCaseNames > 0 {
+		if _, known := gm.caseNameSet[caseName]; !known {
+			if len(gm.caseNameSet) >= gm.MaxCaseNames {
+				gm.dropCaseNameLocked(caseName)
+				return OtherCaseName
+			}
+			gm.admitCaseNameLocked(caseName)
+		}
+	}
+
+	return caseName
+}
+
+// admitCaseNameLocked counts caseName towards MaxCaseNames. Caller must hold gm.mu.
+func (gm *GoroutineManager) admitCaseNameLocked(caseName string) {
+	if gm.caseNameSet == nil {
+		gm.caseNameSet = make(map[string]struct{})
+	}
+	gm.caseNameSet[caseName] = struct{}{}
+}
+
+// caseMapFullLocked reports whether a map keyed by case names that already
+// holds size entries is at MaxCaseNames, so a new name must not be added.
+// Caller must hold gm.mu.
+func (gm *GoroutineManager) caseMapFullLocked(size int) bool {
+	return gm.MaxCaseNames > 0 && size >= gm.MaxCaseNames
+}
+
+// dropCaseNameLocked counts a distinct name folded into OtherCaseName, only its
+// hash is kept. Caller must hold gm.mu.
+func (gm *GoroutineManager) dropCaseNameLocked(caseName string) {
+	if len(gm.droppedNames) >= maxDroppedNames {
+		return
+	}
+	if gm.droppedNames == nil {
+		gm.droppedNames = make(map[uint64]struct{})
+	}
+	gm.droppedNames[maphash.String(gm.hashSeed, caseName)] = struct{}{}
+}
@@ -0,0 +1,121 @@
+//go:build !idlespy_off
+
+package tracker
+
+import (
+	"log"
+	"slices"
+	"time"
+)
+
+// maxWarnedCases bounds how many unregistered case names are warned about
+const maxWarnedCases = 100
+
+// RegisterCase declares a select case up front and returns its handle.
+// Recording through TrackCase indexes directly into a per-goroutine slot, so
+// with MaxLatencySamples set no allocation happens per hit once the slot is
+// in use. Registering the same name twice returns the same handle.
+func (gm *GoroutineManager) RegisterCase(caseName string) CaseHandle {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if handle, exists := gm.caseHandles[caseName]; exists {
+		return handle
+	}
+
+	if gm.caseHandles == nil {
+		gm.caseHandles = make(map[string]CaseHandle)
+	}
+	names := gm.registeredCases()
+	handle := CaseHandle(len(names))
+	names = append(slices.Clone(names), caseName)
+	gm.caseNames.Store(&names)
+	gm.caseHandles[caseName] = handle
+	gm.admitCaseNameLocked(caseName)
+	if gm.Sampling.enabled() {
+		gm.storeSampler(caseName, newSampler(gm.Sampling), false)
+	}
+
+	return handle
+}
+
+// TrackCase records statistics for a select case registered with RegisterCase
+func (gm *GoroutineManager) TrackCase(handle CaseHandle, duration time.Duration, id GoroutineId) {
+	gm.TrackCaseHandled(handle, duration, 0, id)
+}
+
+// TrackCaseHandled records the wait and handling time of a select case
+// registered with RegisterCase
+func (gm *GoroutineManager) TrackCaseHandled(handle CaseHandle, wait, handling time.Duration, id GoroutineId) {
+	if !gm.Enabled {
+		return
+	}
+
+	entered := gm.overheadStart()
+
+	names := gm.registeredCases()
+	if handle < 0 || int(handle) >= len(names) {
+		return
+	}
+
+	weight, ok := gm.sample(names[handle])
+	if !ok {
+		return
+	}
+
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	stats := gm.goroutineStatsLocked(id)
+	gm.caseSlotLocked(stats, handle).AddSampledHit(wait, handling, weight)
+	stats.accountHitLocked(wait, handling, weight)
+	gm.addOverheadLocked(stats, entered)
+}
+
+// registeredCases returns the names of the registered cases, indexed by handle
+func (gm *GoroutineManager) registeredCases() []string {
+	if names := gm.caseNames.Load(); names != nil {
+		return *names
+	}
+	return nil
+}
+
+// caseSlotLocked returns the goroutine's stats for a registered case, creating
+// them on first use. Caller must hold gm.mu.
+func (gm *GoroutineManager) caseSlotLocked(stats *GoroutineStats, handle CaseHandle) *SelectStats {
+	names := gm.registeredCases()
+	if int(handle) >= len(stats.cases) {
+		stats.cases = append(stats.cases, make([]*SelectStats, len(names)-len(stats.cases))...)
+	}
+
+	slot := stats.cases[handle]
+	if slot == nil {
+		slot = gm.selectStatsLocked(stats, names[handle])
+		stats.cases[handle] = slot
+	}
+	return slot
+}
+
+// acceptUnregisteredLocked reports whether hits of a case that was not
+// registered are recorded, warning once per name when cases are registered.
+// Caller must hold gm.mu.
+func (gm *GoroutineManager) acceptUnregisteredLocked(caseName string) bool {
+	if len(gm.registeredCases()) == 0 {
+		return true
+	}
+
+	if _, warned := gm.warnedCases[caseName]; !warned && len(gm.warnedCases) < maxWarnedCases {
+		if gm.warnedCases == nil {
+			gm.warnedCases = make(map[string]struct{})
+		}
+		gm.warnedCases[caseName] = struct{}{}
+
+		if gm.StrictCases {
+			log.Printf("IdleSpy: dropping hits of unregistered select case %q", caseName)
+		} else {
+			log.Printf("IdleSpy: select case %q was not registered with RegisterCase", caseName)
+		}
+	}
+
+	return !gm.StrictCases
+}
@@ -0,0 +1,44 @@
+package tracker
+
+import "time"
+
+// GetBlockedCategories classifies the goroutine's blocked time by the kind of
+// the cases it was blocked in
+func (gs *GoroutineStats) GetBlockedCategories() BlockedCategories {
+	return blockedCategories(gs.SelectStats)
+}
+
+// GetBlockedCategories classifies the rolled up goroutines' blocked time by
+// the kind of the cases they were blocked in
+func (g *GroupStats) GetBlockedCategories() BlockedCategories {
+	return blockedCategories(g.SelectStats)
+}
+
+// GetTotal returns the blocked time of every category
+func (bc BlockedCategories) GetTotal() time.Duration {
+	return bc.InputStarved + bc.OutputBackpressured + bc.TimerIdle + bc.Cancellation + bc.Unclassified
+}
+
+// blockedCategories classifies the blocked time of the top-level cases, nested
+// cases are part of their parent's category
+func blockedCategories(selectStats map[string]*SelectStats) BlockedCategories {
+	var categories BlockedCategories
+	for _, stats := range selectStats {
+		if _, hasParent := selectStats[stats.Parent]; stats.Parent != "" && hasParent {
+			continue
+		}
+		switch stats.Kind {
+		case KindReceive:
+			categories.InputStarved += stats.BlockedCaseTime
+		case KindSend:
+			categories.OutputBackpressured += stats.BlockedCaseTime
+		case KindTimer:
+			categories.TimerIdle += stats.BlockedCaseTime
+		case KindContextDone:
+			categories.Cancellation += stats.BlockedCaseTime
+		default:
+			categories.Unclassified += stats.BlockedCaseTime
+		}
+	}
+	return categories
+}
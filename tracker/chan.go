@@ -0,0 +1,211 @@
+//go:build !idlespy_off
+
+package tracker
+
+import "time"
+
+// NewChan creates a channel with the given buffer size whose sends and
+// receives are timed. Waits are recorded under name on the channel and, as
+// the cases name+ChanSendSuffix and name+ChanRecvSuffix, on the tracked
+// goroutine that waited. Channels sharing a name share their statistics.
+// A channel created with a nil manager or while tracking is disabled is
+// never recorded.
+func NewChan[T any](gm *GoroutineManager, name string, size int) *Chan[T] {
+	c := &Chan[T]{gm: gm, name: name, ch: make(chan T, size)}
+	if gm == nil || !gm.Enabled {
+		return c
+	}
+
+	gm.mu.Lock()
+	c.record = gm.channelRecordLocked(name)
+	c.record.mu.Lock()
+	c.record.stats.Capacity = size
+	c.record.stats.length = c.Len
+	c.record.mu.Unlock()
+	gm.defaultCaseKindLocked(name+ChanSendSuffix, KindSend)
+	gm.defaultCaseKindLocked(name+ChanRecvSuffix, KindReceive)
+	gm.mu.Unlock()
+	return c
+}
+
+// Send sends v on the channel, recording how long it waited for room
+func (c *Chan[T]) Send(v T) {
+	if !c.tracked() {
+		c.ch <- v
+		return
+	}
+	c.send(v, c.gm.callerID())
+}
+
+// SendFrom is Send for the tracked goroutine id, it saves looking up the
+// calling goroutine on every send
+func (c *Chan[T]) SendFrom(v T, id GoroutineId) {
+	if !c.tracked() {
+		c.ch <- v
+		return
+	}
+	c.send(v, id)
+}
+
+func (c *Chan[T]) send(v T, id GoroutineId) {
+	start := time.Now()
+	c.ch <- v
+	c.gm.trackChannelOp(c.record, c.name, true, time.Since(start), len(c.ch), id)
+}
+
+// Recv receives a value from the channel, recording how long it waited for
+// one. ok is false when the channel is closed and drained.
+func (c *Chan[T]) Recv() (v T, ok bool) {
+	if !c.tracked() {
+		v, ok = <-c.ch
+		return v, ok
+	}
+	return c.recv(c.gm.callerID())
+}
+
+// RecvFrom is Recv for the tracked goroutine id, it saves looking up the
+// calling goroutine on every receive
+func (c *Chan[T]) RecvFrom(id GoroutineId) (v T, ok bool) {
+	if !c.tracked() {
+		v, ok = <-c.ch
+		return v, ok
+	}
+	return c.recv(id)
+}
+
+func (c *Chan[T]) recv(id GoroutineId) (v T, ok bool) {
+	start := time.Now()
+	v, ok = <-c.ch
+	c.gm.trackChannelOp(c.record, c.name, false, time.Since(start), len(c.ch), id)
+	if !ok && id != 0 {
+		c.gm.sawClosedChan(id)
+	}
+	return v, ok
+}
+
+// Close closes the channel, recording when and by which goroutine
+func (c *Chan[T]) Close() {
+	close(c.ch)
+	if !c.tracked() {
+		return
+	}
+
+	id := getGoroutineID()
+	c.record.mu.Lock()
+	defer c.record.mu.Unlock()
+	c.record.stats.ClosedAt = time.Now()
+	c.record.stats.ClosedBy = id
+}
+
+// tracked reports whether operations on the channel are recorded: it has a
+// record and its manager is enabled
+func (c *Chan[T]) tracked() bool {
+	return c.record != nil && c.gm.Enabled
+}
+
+// C returns the underlying channel for use in select statements and range
+// loops, operations on it are not recorded
+func (c *Chan[T]) C() chan T {
+	return c.ch
+}
+
+// Len returns the number of values buffered in the channel
+func (c *Chan[T]) Len() int {
+	return len(c.ch)
+}
+
+// Cap returns the buffer size of the channel
+func (c *Chan[T]) Cap() int {
+	return cap(c.ch)
+}
+
+// trackChannelOp records a send or receive that waited wait and left
+// occupancy values buffered on the channel's record, and attributes the wait
+// to the goroutine id unless it is zero. Only the attribution takes gm.mu.
+// It must be called directly from send or recv.
+func (gm *GoroutineManager) trackChannelOp(record *channelRecord, name string, send bool, wait time.Duration, occupancy int, id GoroutineId) {
+	entered := gm.overheadStart()
+
+	record.mu.Lock()
+	channel := &record.stats
+	if send {
+		channel.Sends++
+		channel.SendWaitTime += wait
+	} else {
+		channel.Receives++
+		channel.RecvWaitTime += wait
+	}
+	channel.OccupancySamples++
+	channel.TotalOccupancy += occupancy
+	channel.MaxOccupancy = max(channel.MaxOccupancy, occupancy)
+	record.mu.Unlock()
+
+	if id == 0 {
+		return
+	}
+	caseName := name + ChanRecvSuffix
+	if send {
+		caseName = name + ChanSendSuffix
+	}
+	var at *CallSite
+	if gm.CaptureCallSites {
+		at = captureCallSite(3)
+	}
+	gm.attribute(id, caseName, at, wait, 0, entered)
+}
+
+// sawClosedChan notes that a receive of the goroutine returned because the
+// channel was closed, so its end is reported as ExitChannelClosed
+func (gm *GoroutineManager) sawClosedChan(id GoroutineId) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if stats, tracked := gm.Stats[id]; tracked && stats.EndTime.IsZero() {
+		stats.sawClosedChan = true
+	}
+}
+
+// channelRecordLocked returns the record of a channel, creating it on first
+// use. Caller must hold gm.mu.
+func (gm *GoroutineManager) channelRecordLocked(name string) *channelRecord {
+	record, exists := gm.channels[name]
+	if !exists {
+		if gm.channels == nil {
+			gm.channels = make(map[string]*channelRecord)
+		}
+		record = &channelRecord{stats: ChannelStats{Name: name}}
+		gm.channels[name] = record
+	}
+	return record
+}
+
+// clone returns a copy of the stats kept in the record
+func (r *channelRecord) clone() *ChannelStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats.clone()
+}
+
+// GetChannelStats returns a copy of the statistics of the channels named name
+func (gm *GoroutineManager) GetChannelStats(name string) *ChannelStats {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	record, exists := gm.channels[name]
+	if !exists {
+		return nil
+	}
+	return record.clone()
+}
+
+// GetAllChannelStats returns a copy of the statistics of every channel, keyed by name
+func (gm *GoroutineManager) GetAllChannelStats() map[string]*ChannelStats {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	channels := make(map[string]*ChannelStats, len(gm.channels))
+	for name, record := range gm.channels {
+		channels[name] = record.clone()
+	}
+	return channels
+}
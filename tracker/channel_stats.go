@@ -0,0 +1,95 @@
+package tracker
+
+import (
+	"slices"
+	"time"
+)
+
+// GetMeanOccupancy returns the average number of values buffered after a send
+// or receive
+func (cs *ChannelStats) GetMeanOccupancy() float64 {
+	if cs.OccupancySamples == 0 {
+		return 0
+	}
+	return float64(cs.TotalOccupancy) / float64(cs.OccupancySamples)
+}
+
+// GetAverageSendWait returns the average time a send waited for room
+func (cs *ChannelStats) GetAverageSendWait() time.Duration {
+	if cs.Sends == 0 {
+		return 0
+	}
+	return cs.SendWaitTime / time.Duration(cs.Sends)
+}
+
+// GetAverageRecvWait returns the average time a receive waited for a value
+func (cs *ChannelStats) GetAverageRecvWait() time.Duration {
+	if cs.Receives == 0 {
+		return 0
+	}
+	return cs.RecvWaitTime / time.Duration(cs.Receives)
+}
+
+// IsClosed reports whether the channel was closed
+func (cs *ChannelStats) IsClosed() bool {
+	return !cs.ClosedAt.IsZero()
+}
+
+// sub removes the operations already present in an earlier copy of the same
+// channel stats, the maximum occupancy is kept
+func (cs *ChannelStats) sub(prev *ChannelStats) {
+	cs.Sends -= prev.Sends
+	cs.SendWaitTime -= prev.SendWaitTime
+	cs.Receives -= prev.Receives
+	cs.RecvWaitTime -= prev.RecvWaitTime
+	cs.OccupancySamples -= prev.OccupancySamples
+	cs.TotalOccupancy -= prev.TotalOccupancy
+	if cs.Occupancy != nil && prev.Occupancy != nil {
+		cs.Occupancy.sub(prev.Occupancy)
+	}
+}
+
+// clone returns a deep copy of the channel stats
+func (cs *ChannelStats) clone() *ChannelStats {
+	c := *cs
+	if cs.Occupancy != nil {
+		occupancy := *cs.Occupancy
+		occupancy.Series = slices.Clone(cs.Occupancy.Series)
+		c.Occupancy = &occupancy
+	}
+	return &c
+}
+
+// reset clears the operations counted so far, keeping what describes the channel
+func (cs *ChannelStats) reset() {
+	*cs = ChannelStats{
+		Name:     cs.Name,
+		Capacity: cs.Capacity,
+		ClosedAt: cs.ClosedAt,
+		ClosedBy: cs.ClosedBy,
+		length:   cs.length,
+	}
+}
+
+// GetMeanFill returns the average sampled fill ratio
+func (oc *OccupancyStats) GetMeanFill() float64 {
+	if oc.Samples == 0 {
+		return 0
+	}
+	return oc.TotalFill / float64(oc.Samples)
+}
+
+// sub removes the samples already present in an earlier copy of the same
+// occupancy stats, the minimum and maximum are kept
+func (oc *OccupancyStats) sub(prev *OccupancyStats) {
+	oc.Samples -= prev.Samples
+	oc.TotalFill -= prev.TotalFill
+	oc.TimeAtFull -= prev.TimeAtFull
+	oc.TimeAtEmpty -= prev.TimeAtEmpty
+	if n := len(prev.Series); n > 0 {
+		after := prev.Series[n-1].Time
+		oc.Series = slices.DeleteFunc(oc.Series, func(p OccupancyPoint) bool {
+			return !p.Time.After(after)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package tracker
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by NewGoroutineManager
+const (
+	EnvEnabled        = "IDLESPY_ENABLED"            // 1/true/on or 0/false/off
+	EnvFormat         = "IDLESPY_FORMAT"             // text or json
+	EnvAction         = "IDLESPY_ACTION"             // print_and_save, save, print or none
+	EnvOutputDir      = "IDLESPY_OUTPUT_DIR"         // directory the reports are written to
+	EnvStreamInterval = "IDLESPY_STREAM_INTERVAL"    // snapshot stream interval, e.g. 10s
+	EnvSampleEvery    = "IDLESPY_SAMPLE_EVERY"       // record one out of every N hits
+	EnvSampleInterval = "IDLESPY_SAMPLE_INTERVAL"    // record at most one hit per interval and case, e.g. 1ms
+	EnvHTTPAddr       = "IDLESPY_HTTP_ADDR"          // address serving live snapshots, e.g. :6070
+	EnvOverhead       = "IDLESPY_MEASURE_OVERHEAD"   // 1/true to report the tracker's own overhead
+	EnvCallSites      = "IDLESPY_CAPTURE_CALL_SITES" // 1/true to capture where each case was recorded
+)
+
+// StreamPath returns the file the snapshot stream started by
+// IDLESPY_STREAM_INTERVAL is written to, under IDLESPY_OUTPUT_DIR
+func StreamPath() string {
+	return filepath.Join(os.Getenv(EnvOutputDir), DefaultStreamPath)
+}
+
+// EnabledByDefault decides whether managers track anything when IDLESPY_ENABLED
+// is not set. It is true so existing code keeps tracking, binaries that ship
+// IdleSpy disabled set it at link time, see linkEnabled.
+var EnabledByDefault = true
+
+// linkEnabled overrides EnabledByDefault without code changes, e.g.
+// -ldflags "-X github.com/AlexsanderHamir/IdleSpy/tracker.linkEnabled=false".
+// It takes the same values as IDLESPY_ENABLED.
+var linkEnabled string
+
+func init() {
+	if linkEnabled == "" {
+		return
+	}
+	if enabled, ok := parseEnabled(linkEnabled); ok {
+		EnabledByDefault = enabled
+	} else {
+		log.Printf("Ignoring invalid link-time default %q", linkEnabled)
+	}
+}
+
+// parseEnabled parses a value of IDLESPY_ENABLED and reports whether it is valid
+func parseEnabled(value string) (enabled, ok bool) {
+	switch strings.ToLower(value) {
+	case "1", "true", "on", "yes":
+		return true, true
+	case "0", "false", "off", "no":
+		return false, true
+	}
+	return false, false
+}
+
+// applyEnv configures the manager from the IDLESPY_* environment variables,
+// invalid values are logged and ignored
+func (gm *GoroutineManager) applyEnv() {
+	gm.Enabled = EnabledByDefault
+	if value, ok := os.LookupEnv(EnvEnabled); ok {
+		if enabled, valid := parseEnabled(value); valid {
+			gm.Enabled = enabled
+		} else {
+			log.Printf("Ignoring invalid %s: %q", EnvEnabled, value)
+		}
+	}
+
+	if !gm.Enabled {
+		return
+	}
+
+	if value := os.Getenv(EnvFormat); value != "" {
+		switch value {
+		case "text", "json":
+			gm.FileType = value
+		default:
+			log.Printf("Ignoring invalid %s: %q", EnvFormat, value)
+		}
+	}
+
+	if value := os.Getenv(EnvAction); value != "" {
+		switch action := Action(value); action {
+		case PrintAndSave, Save, Print, None:
+			gm.Action = action
+		default:
+			log.Printf("Ignoring invalid %s: %q", EnvAction, value)
+		}
+	}
+
+	gm.OutputDir = os.Getenv(EnvOutputDir)
+
+	if value := os.Getenv(EnvOverhead); value != "" {
+		measure, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("Ignoring invalid %s: %q", EnvOverhead, value)
+		} else {
+			gm.MeasureOverhead = measure
+		}
+	}
+
+	if value := os.Getenv(EnvCallSites); value != "" {
+		capture, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("Ignoring invalid %s: %q", EnvCallSites, value)
+		} else {
+			gm.CaptureCallSites = capture
+		}
+	}
+
+	if value := os.Getenv(EnvSampleEvery); value != "" {
+		every, err := strconv.Atoi(value)
+		if err != nil || every < 1 {
+			log.Printf("Ignoring invalid %s: %q", EnvSampleEvery, value)
+		} else {
+			gm.Sampling.Every = every
+		}
+	}
+
+	if value := os.Getenv(EnvSampleInterval); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval < 0 {
+			log.Printf("Ignoring invalid %s: %q", EnvSampleInterval, value)
+		} else {
+			gm.Sampling.Interval = interval
+		}
+	}
+
+	if value := os.Getenv(EnvStreamInterval); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			log.Printf("Ignoring invalid %s: %q", EnvStreamInterval, value)
+		} else if err := gm.StartStream(StreamPath(), interval); err != nil {
+			log.Printf("Error starting stream: %v", err)
+		}
+	}
+
+	// bind before returning, so Done always finds the server to close
+	if addr := os.Getenv(EnvHTTPAddr); addr != "" {
+		server, listener, err := gm.listen(addr)
+		if err != nil {
+			log.Printf("Error serving stats: %v", err)
+			return
+		}
+
+		go func() {
+			if err := serve(server, listener); err != nil {
+				log.Printf("Error serving stats: %v", err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,121 @@
+package tracker
+
+import (
+	"maps"
+	"slices"
+)
+
+// GetExitReason returns how the goroutine ended, empty while it is running
+func (gs *GoroutineStats) GetExitReason() ExitReason {
+	return gs.ExitReason
+}
+
+// GetPanic returns the panic that ended the goroutine, nil if it did not panic
+func (gs *GoroutineStats) GetPanic() *Panic {
+	return gs.Panic
+}
+
+// inferExitReason returns the reason a goroutine ended without being told one
+func (gs *GoroutineStats) inferExitReason() ExitReason {
+	switch {
+	case gs.WasCancelled():
+		return ExitCancelled
+	case gs.sawClosedChan:
+		return ExitChannelClosed
+	default:
+		return ExitReturned
+	}
+}
+
+// GetTotal returns how many goroutines ended
+func (es *ExitStats) GetTotal() int {
+	total := 0
+	for _, count := range es.Reasons {
+		total += count
+	}
+	return total
+}
+
+// GetCount returns how many goroutines ended for reason
+func (es *ExitStats) GetCount(reason ExitReason) int {
+	return es.Reasons[reason]
+}
+
+// Exits returns how the ended goroutines and groups in the snapshot ended,
+// keyed by group. Goroutines without a group belong to DefaultGroup.
+func (snap *Snapshot) Exits() map[string]*ExitStats {
+	exits := make(map[string]*ExitStats)
+	groupOf := func(name string) *ExitStats {
+		if name == "" {
+			name = DefaultGroup
+		}
+		es, exists := exits[name]
+		if !exists {
+			es = &ExitStats{Group: name}
+			exits[name] = es
+		}
+		return es
+	}
+
+	for _, stats := range snap.Stats {
+		if stats.ExitReason != "" {
+			groupOf(stats.Group).add(stats)
+		}
+	}
+	for name, group := range snap.Groups {
+		if group.Exits != nil {
+			groupOf(name).merge(group.Exits)
+		}
+	}
+	return exits
+}
+
+// add counts the exit of an ended goroutine
+func (es *ExitStats) add(stats *GoroutineStats) {
+	if es.Reasons == nil {
+		es.Reasons = make(map[ExitReason]int)
+	}
+	es.Reasons[stats.ExitReason]++
+	if stats.Panic != nil && len(es.Panics) < maxPanics {
+		es.Panics = append(es.Panics, *stats.Panic)
+	}
+}
+
+// merge adds the exits of other
+func (es *ExitStats) merge(other *ExitStats) {
+	if es.Reasons == nil {
+		es.Reasons = make(map[ExitReason]int)
+	}
+	for reason, count := range other.Reasons {
+		es.Reasons[reason] += count
+	}
+	for _, p := range other.Panics {
+		if len(es.Panics) < maxPanics {
+			es.Panics = append(es.Panics, p)
+		}
+	}
+}
+
+// clone returns a deep copy of the exit stats, nil stays nil
+func (es *ExitStats) clone() *ExitStats {
+	if es == nil {
+		return nil
+	}
+	return &ExitStats{
+		Group:   es.Group,
+		Reasons: maps.Clone(es.Reasons),
+		Panics:  slices.Clone(es.Panics),
+	}
+}
+
+// sub removes the exits already present in an earlier copy of the same exit
+// stats, the panics kept by both are dropped
+func (es *ExitStats) sub(prev *ExitStats) {
+	for reason, count := range prev.Reasons {
+		es.Reasons[reason] -= count
+		if es.Reasons[reason] == 0 {
+			delete(es.Reasons, reason)
+		}
+	}
+	es.Panics = es.Panics[min(len(prev.Panics), len(es.Panics)):]
+}
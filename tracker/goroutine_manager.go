@@ -1,41 +1,61 @@
+//go:build !idlespy_off
+
 package tracker
 
 import (
 	"fmt"
+	"hash/maphash"
 	"maps"
+	"os"
 	"sync"
 	"time"
 )
 
-// NewGoroutineManager creates a new goroutine statistics manager
+// NewGoroutineManager creates a new goroutine statistics manager, configured
+// from the IDLESPY_* environment variables when they are set
 func NewGoroutineManager() *GoroutineManager {
-	return &GoroutineManager{
-		Stats: make(map[GoroutineId]*GoroutineStats),
-		mu:    &sync.RWMutex{},
-		Wg:    &sync.WaitGroup{},
+	gm := &GoroutineManager{
+		Stats:         make(map[GoroutineId]*GoroutineStats),
+		mu:            &sync.RWMutex{},
+		flushMu:       &sync.Mutex{},
+		streamMu:      &sync.Mutex{},
+		Wg:            &sync.WaitGroup{},
+		intervalStart: time.Now(),
+		hashSeed:      maphash.MakeSeed(),
 	}
+	gm.applyEnv()
+	return gm
 }
 
-// TrackGoroutineStart records the start of a goroutine tracking
+// TrackGoroutineStart records the start of a goroutine tracking and adds it
+// to gm.Wg, TrackGoroutineEnd releases it
 func (gm *GoroutineManager) TrackGoroutineStart() GoroutineId {
-	gm.mu.Lock()
-	defer gm.mu.Unlock()
+	return gm.TrackGoroutineStartInGroup("")
+}
 
-	id := getGoroutineID()
-	if _, exists := gm.Stats[id]; !exists {
-		gm.Stats[id] = &GoroutineStats{
-			GoroutineId: id,
-			SelectStats: make(map[string]*SelectStats),
-			StartTime:   time.Now(),
-		}
-	}
+// TrackGoroutineEnd records the end of a goroutine. Its exit reason is
+// ExitCancelled if the context registered with WatchContext was cancelled,
+// ExitChannelClosed if a receive on a closed Chan returned, ExitReturned
+// otherwise.
+func (gm *GoroutineManager) TrackGoroutineEnd(id GoroutineId) {
+	gm.trackEnd(id, "", nil)
+}
 
-	gm.Wg.Add(1)
-	return id
+// TrackGoroutineEndReason records the end of a goroutine that ended for reason
+func (gm *GoroutineManager) TrackGoroutineEndReason(id GoroutineId, reason ExitReason) {
+	gm.trackEnd(id, reason, nil)
 }
 
-// TrackGoroutineEnd records the end of a goroutine
-func (gm *GoroutineManager) TrackGoroutineEnd(id GoroutineId) {
+// trackEnd records the end of a goroutine, an empty reason is inferred from
+// what the goroutine saw. p is the panic that ended it, if any.
+func (gm *GoroutineManager) trackEnd(id GoroutineId, reason ExitReason, p *Panic) {
+	if !gm.Enabled {
+		gm.Wg.Done()
+		return
+	}
+
+	entered := gm.overheadStart()
+
 	gm.mu.Lock()
 	defer func() {
 		gm.Wg.Done()
@@ -43,32 +63,204 @@ func (gm *GoroutineManager) TrackGoroutineEnd(id GoroutineId) {
 	}()
 
 	if stats, exists := gm.Stats[id]; exists {
+		gm.addOverheadLocked(stats, entered)
+		finished := !stats.EndTime.IsZero()
 		stats.EndTime = time.Now()
+		if !finished {
+			gm.running.Add(-1)
+			gm.endWatchLocked(stats)
+			stats.endOpenSpansLocked()
+			if reason == "" {
+				reason = stats.inferExitReason()
+			}
+			stats.ExitReason = reason
+			stats.Panic = p
+			gm.retainLocked(stats)
+		}
 	}
 }
 
 // TrackSelectCase records statistics for a select case
 func (gm *GoroutineManager) TrackSelectCase(caseName string, duration time.Duration, id GoroutineId) {
+	gm.trackSelect("", "", caseName, gm.callSite(caseName), duration, 0, id)
+}
+
+// TrackSelectCaseHandled records a select case that waited wait until it was
+// ready and whose handler then ran for handling
+func (gm *GoroutineManager) TrackSelectCaseHandled(caseName string, wait, handling time.Duration, id GoroutineId) {
+	gm.trackSelect("", "", caseName, gm.callSite(caseName), wait, handling, id)
+}
+
+// TrackNestedSelectCase records a select case nested inside the case parent,
+// i.e. a select that ran while parent's duration was being measured. It is
+// reported as parent > caseName and left out of the goroutine's totals, since
+// its time is already part of parent's. Parents nested themselves are named
+// by their full reported name.
+func (gm *GoroutineManager) TrackNestedSelectCase(parent, caseName string, duration time.Duration, id GoroutineId) {
+	gm.trackSelect(parent, "", caseName, gm.callSite(caseName), duration, 0, id)
+}
+
+// trackSelect records a hit of a top-level case, of a case nested in parent
+// or of a case of the select statement site, whichever is not empty. An empty
+// case name is replaced by the call site at.
+func (gm *GoroutineManager) trackSelect(parent, site, caseName string, at *CallSite, wait, handling time.Duration, id GoroutineId) {
+	if !gm.Enabled {
+		return
+	}
+
+	entered := gm.overheadStart()
+
+	if caseName == "" {
+		caseName = at.String()
+	}
+
+	bareName := caseName
+	if parent != "" {
+		caseName = parent + NestedCaseSeparator + caseName
+	} else if site != "" {
+		caseName = site + SiteCaseSeparator + caseName
+	}
+
+	weight, ok := gm.sample(caseName)
+	if !ok {
+		return
+	}
+
 	gm.mu.Lock()
 	defer gm.mu.Unlock()
 
+	stats := gm.goroutineStatsLocked(id)
+	var selectStats *SelectStats
+	// nested and site hits of a registered case are recorded under their
+	// composed name but are not unregistered
+	if handle, registered := gm.caseHandles[bareName]; registered && caseName == bareName {
+		selectStats = gm.caseSlotLocked(stats, handle)
+	} else if registered || gm.acceptUnregisteredLocked(caseName) {
+		caseName = gm.admitCaseLocked(stats, caseName)
+		if caseName == OtherCaseName && parent != "" {
+			// folded nested cases stay under their parent so they are not
+			// counted twice
+			caseName = parent + NestedCaseSeparator + OtherCaseName
+		}
+		selectStats = gm.selectStatsLocked(stats, caseName)
+		if caseName != OtherCaseName {
+			selectStats.Parent = parent
+			selectStats.Site = site
+			if selectStats.Kind == "" {
+				selectStats.Kind = gm.caseKindLocked(bareName)
+			}
+		}
+	}
+	if selectStats != nil && selectStats.CallSite == nil {
+		selectStats.CallSite = at
+	}
+	if selectStats != nil {
+		selectStats.AddSampledHit(wait, handling, weight)
+		if selectStats.Parent == "" {
+			stats.accountHitLocked(wait, handling, weight)
+		}
+	}
+	gm.addOverheadLocked(stats, entered)
+}
+
+// attribute samples and records a wait that did not happen in a select as a
+// hit of caseName on the goroutine id, see attributeLocked
+func (gm *GoroutineManager) attribute(id GoroutineId, caseName string, at *CallSite, wait, handling time.Duration, entered time.Time) {
+	weight, sampled := gm.sample(caseName)
+	if !sampled {
+		return
+	}
+
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.attributeLocked(id, caseName, at, wait, handling, weight, entered)
+}
+
+// callerID returns the id of the calling goroutine, or zero without looking
+// it up when no tracked goroutine is running
+func (gm *GoroutineManager) callerID() GoroutineId {
+	if gm.running.Load() == 0 {
+		return 0
+	}
+	return getGoroutineID()
+}
+
+// attributeLocked records a wait that did not happen in a select as a hit of
+// caseName on the goroutine that waited, when that goroutine is tracked and
+// still running. Caller must hold gm.mu.
+func (gm *GoroutineManager) attributeLocked(id GoroutineId, caseName string, at *CallSite, wait, handling time.Duration, weight int, entered time.Time) {
+	stats, tracked := gm.Stats[id]
+	if !tracked || !stats.EndTime.IsZero() {
+		return
+	}
+	caseName = gm.admitCaseLocked(stats, caseName)
+	selectStats := gm.selectStatsLocked(stats, caseName)
+	if selectStats.CallSite == nil {
+		selectStats.CallSite = at
+	}
+	selectStats.AddSampledHit(wait, handling, weight)
+	stats.accountHitLocked(wait, handling, weight)
+	gm.addOverheadLocked(stats, entered)
+}
+
+// goroutineStatsLocked returns the stats of a goroutine, creating them on
+// first use. Caller must hold gm.mu.
+func (gm *GoroutineManager) goroutineStatsLocked(id GoroutineId) *GoroutineStats {
 	stats, exists := gm.Stats[id]
 	if !exists {
 		stats = &GoroutineStats{
 			GoroutineId: id,
 			SelectStats: make(map[string]*SelectStats),
 			StartTime:   time.Now(),
+			cases:       make([]*SelectStats, len(gm.registeredCases())),
 		}
 		gm.Stats[id] = stats
+		gm.running.Add(1)
 	}
+	return stats
+}
 
+// selectStatsLocked returns the stats of a goroutine's select case, creating
+// them on first use. Caller must hold gm.mu.
+func (gm *GoroutineManager) selectStatsLocked(stats *GoroutineStats, caseName string) *SelectStats {
 	selectStats, exists := stats.SelectStats[caseName]
 	if !exists {
-		selectStats = &SelectStats{}
+		selectStats = gm.newSelectStats(gm.MaxLatencySamples)
+		selectStats.Kind = gm.caseKindLocked(caseName)
 		stats.SelectStats[caseName] = selectStats
 	}
+	return selectStats
+}
 
-	selectStats.AddLatency(duration)
+// newSelectStats creates empty select stats keeping at most maxLatencies
+// latencies, with the manager's windows
+func (gm *GoroutineManager) newSelectStats(maxLatencies int) *SelectStats {
+	selectStats := &SelectStats{maxLatencies: maxLatencies}
+	if maxLatencies > 0 {
+		selectStats.latencies = make([]time.Duration, 0, maxLatencies)
+	}
+	if len(gm.Windows) > 0 {
+		selectStats.window = newRollingWindow(gm.Windows)
+	}
+	return selectStats
+}
+
+// overheadStart returns the time a recording method was entered, or the zero
+// time when overhead is not measured
+func (gm *GoroutineManager) overheadStart() time.Time {
+	if !gm.MeasureOverhead {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// addOverheadLocked charges the time spent since entered, including waiting
+// for gm.mu, to the goroutine. Caller must hold gm.mu.
+func (gm *GoroutineManager) addOverheadLocked(stats *GoroutineStats, entered time.Time) {
+	if entered.IsZero() {
+		return
+	}
+	stats.Overhead += time.Since(entered)
 }
 
 // GetGoroutineStats returns statistics for a specific goroutine
@@ -85,14 +277,132 @@ func (gm *GoroutineManager) GetAllStats() map[GoroutineId]*GoroutineStats {
 	return maps.Clone(gm.Stats)
 }
 
-// Done waits for all goroutines to finish and then saves the final stats
+// Snapshot returns a deep copy of the statistics collected since the manager
+// was created or last reset
+func (gm *GoroutineManager) Snapshot() *Snapshot {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	return gm.snapshotLocked(time.Now())
+}
+
+// SnapshotAndReset atomically copies the current statistics and clears all
+// counters, so the next snapshot only covers the time after this call.
+// Finished goroutines and rolled up groups are dropped, running goroutines
+// and channels keep being tracked. The rolling windows of the select cases
+// are cleared along with the counters, so windowed stats also restart.
+func (gm *GoroutineManager) SnapshotAndReset() *Snapshot {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	snap := gm.snapshotLocked(time.Now())
+	for id, stats := range gm.Stats {
+		if !stats.EndTime.IsZero() {
+			delete(gm.Stats, id)
+			continue
+		}
+		stats.SelectStats = make(map[string]*SelectStats)
+		stats.Overhead = 0
+		stats.Spans = nil
+		stats.SpanTime = 0
+		stats.blockedInSpans = 0
+		stats.handlingOutsideSpans = 0
+		clear(stats.cases)
+		// the open spans were counted up to the snapshot, the next interval
+		// counts them from there
+		for i := range stats.openSpans {
+			stats.openSpans[i].start = snap.Taken
+			stats.openSpans[i].children = 0
+		}
+	}
+	for _, record := range gm.channels {
+		record.mu.Lock()
+		record.stats.reset()
+		record.mu.Unlock()
+	}
+	for _, record := range gm.locks {
+		record.mu.Lock()
+		record.stats = LockStats{Name: record.stats.Name}
+		record.mu.Unlock()
+	}
+	gm.exemplars = nil
+	gm.groups = nil
+	gm.intervalStart = snap.Taken
+
+	return snap
+}
+
+// snapshotLocked copies the stats, clamping lifetimes to the current interval.
+// Caller must hold gm.mu.
+func (gm *GoroutineManager) snapshotLocked(now time.Time) *Snapshot {
+	snap := &Snapshot{
+		Since:            gm.intervalStart,
+		Taken:            now,
+		Stats:            make(map[GoroutineId]*GoroutineStats, len(gm.Stats)),
+		DroppedCaseNames: len(gm.droppedNames),
+		spinRate:         gm.SpinRate,
+		spinRatio:        gm.SpinRatio,
+	}
+	for id, stats := range gm.Stats {
+		snap.Stats[id] = stats.clone(snap.Since, snap.Taken)
+	}
+	if len(gm.groups) > 0 {
+		snap.Groups = make(map[string]*GroupStats, len(gm.groups))
+		for name, group := range gm.groups {
+			snap.Groups[name] = group.clone(snap.Taken)
+		}
+	}
+	if len(gm.channels) > 0 {
+		snap.Channels = make(map[string]*ChannelStats, len(gm.channels))
+		for name, record := range gm.channels {
+			snap.Channels[name] = record.clone()
+		}
+	}
+	for name, record := range gm.locks {
+		// locks unused since the last reset are left out
+		if lock := record.clone(); lock.GetAcquisitions() > 0 {
+			if snap.Locks == nil {
+				snap.Locks = make(map[string]*LockStats, len(gm.locks))
+			}
+			snap.Locks[name] = lock
+		}
+	}
+	return snap
+}
+
+// Done waits for all goroutines to finish, stops the stream and the stats
+// server and then saves the final stats
 func (gm *GoroutineManager) Done() error {
 	gm.Wg.Wait()
+	gm.stopHandlingSignals()
+	gm.StopOccupancySampling()
+
+	if err := gm.StopStream(); err != nil {
+		return fmt.Errorf("error closing stream: %w", err)
+	}
 
-	if gm.Action == None {
+	if err := gm.stopServing(); err != nil {
+		return fmt.Errorf("error closing stats server: %w", err)
+	}
+
+	return gm.Flush()
+}
+
+// Flush writes the reports selected by FileType and Action without waiting
+// for the tracked goroutines to finish
+func (gm *GoroutineManager) Flush() error {
+	if !gm.Enabled || gm.Action == None {
 		return nil
 	}
 
+	gm.flushMu.Lock()
+	defer gm.flushMu.Unlock()
+
+	if gm.OutputDir != "" {
+		if err := os.MkdirAll(gm.OutputDir, 0755); err != nil {
+			return fmt.Errorf("error creating output directory: %w", err)
+		}
+	}
+
 	switch gm.FileType {
 	case "text":
 		gm.handleTextActions()
@@ -104,3 +414,14 @@ func (gm *GoroutineManager) Done() error {
 
 	return nil
 }
+
+// Now returns the current time, use it with Since to time select cases so the
+// measurement compiles away with the idlespy_off build tag
+func Now() time.Time {
+	return time.Now()
+}
+
+// Since returns the time elapsed since t
+func Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
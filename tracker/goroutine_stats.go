@@ -7,6 +7,7 @@ import (
 	"log"
 	"maps"
 	"os"
+	"slices"
 	"strings"
 	"time"
 )
@@ -19,15 +20,165 @@ func (gs *GoroutineStats) GetGoroutineLifetime() time.Duration {
 	return gs.EndTime.Sub(gs.StartTime)
 }
 
-// GetTotalSelectTime returns the total time spent in select cases for a goroutine
+// GetTotalSelectTime returns the total time spent in select cases for a goroutine,
+// nested cases are already part of their parent's time
 func (gs *GoroutineStats) GetTotalSelectBlockedTime() time.Duration {
+	return totalBlockedTime(gs.SelectStats)
+}
+
+// GetSelfBlockedTime returns a case's blocked time without the time of the
+// cases nested in it
+func (gs *GoroutineStats) GetSelfBlockedTime(caseName string) time.Duration {
+	return selfBlockedTime(gs.SelectStats, caseName)
+}
+
+// totalBlockedTime sums the blocked time of the top-level cases. Nested cases
+// are only counted when their parent was not recorded.
+func totalBlockedTime(selectStats map[string]*SelectStats) time.Duration {
 	var total time.Duration
-	for _, stats := range gs.SelectStats {
+	for _, stats := range selectStats {
+		if _, hasParent := selectStats[stats.Parent]; stats.Parent != "" && hasParent {
+			continue
+		}
 		total += stats.BlockedCaseTime
 	}
 	return total
 }
 
+// selfBlockedTime returns a case's blocked time without the time of the cases
+// nested in it
+func selfBlockedTime(selectStats map[string]*SelectStats, caseName string) time.Duration {
+	caseStats, exists := selectStats[caseName]
+	if !exists {
+		return 0
+	}
+
+	self := caseStats.BlockedCaseTime
+	for _, child := range selectStats {
+		if child.Parent == caseName {
+			self -= child.BlockedCaseTime
+		}
+	}
+	return max(self, 0)
+}
+
+// caseTree orders the cases so each one is followed by the cases nested in
+// it, returning the names along with their nesting depth
+func caseTree(selectStats map[string]*SelectStats) ([]string, []int) {
+	children := make(map[string][]string)
+	var roots []string
+	for caseName, stats := range selectStats {
+		if _, hasParent := selectStats[stats.Parent]; stats.Parent != "" && hasParent {
+			children[stats.Parent] = append(children[stats.Parent], caseName)
+		} else {
+			roots = append(roots, caseName)
+		}
+	}
+
+	var names []string
+	var depths []int
+	var visit func(caseName string, depth int)
+	visit = func(caseName string, depth int) {
+		names = append(names, caseName)
+		depths = append(depths, depth)
+		nested := children[caseName]
+		slices.Sort(nested)
+		for _, child := range nested {
+			visit(child, depth+1)
+		}
+	}
+	slices.Sort(roots)
+	for _, root := range roots {
+		visit(root, 0)
+	}
+	return names, depths
+}
+
+// GetTotalHandlingTime returns the time the goroutine spent handling select cases
+func (gs *GoroutineStats) GetTotalHandlingTime() time.Duration {
+	var total time.Duration
+	for _, stats := range gs.SelectStats {
+		total += stats.HandlingTime
+	}
+	return total
+}
+
+// GetWorkingTime returns the time the goroutine spent working: its outermost
+// spans without the select wait recorded inside them, plus the select handling
+// time recorded outside of any span
+func (gs *GoroutineStats) GetWorkingTime() time.Duration {
+	return max(gs.SpanTime-gs.blockedInSpans, 0) + gs.handlingOutsideSpans
+}
+
+// endSpanLocked ends the innermost open span at now, charging its duration to
+// its parent or to the goroutine's span time. ended is false when the span is
+// only counted up to a snapshot, its time is kept but it is not counted as a
+// span that ended. Caller must hold gm.mu.
+func (gs *GoroutineStats) endSpanLocked(now time.Time, ended bool) {
+	last := len(gs.openSpans) - 1
+	open := gs.openSpans[last]
+	gs.openSpans = gs.openSpans[:last]
+
+	duration := now.Sub(open.start)
+	if gs.Spans == nil {
+		gs.Spans = make(map[string]*SpanStats)
+	}
+	spanStats, exists := gs.Spans[open.name]
+	if !exists {
+		spanStats = &SpanStats{}
+		gs.Spans[open.name] = spanStats
+	}
+	if ended {
+		spanStats.Count++
+	}
+	spanStats.TotalTime += duration
+	spanStats.SelfTime += duration - open.children
+
+	if last > 0 {
+		gs.openSpans[last-1].children += duration
+	} else {
+		gs.SpanTime += duration
+	}
+}
+
+// endOpenSpansLocked ends the spans still open when the goroutine ends.
+// Caller must hold gm.mu.
+func (gs *GoroutineStats) endOpenSpansLocked() {
+	for len(gs.openSpans) > 0 {
+		gs.endSpanLocked(gs.EndTime, true)
+	}
+}
+
+// GetUnaccountedTime returns the part of the goroutine's lifetime that was
+// neither blocked nor working
+func (gs *GoroutineStats) GetUnaccountedTime() time.Duration {
+	return max(gs.GetGoroutineLifetime()-gs.GetTotalSelectBlockedTime()-gs.GetWorkingTime(), 0)
+}
+
+// GetSpanStats returns statistics for a specific work span
+func (gs *GoroutineStats) GetSpanStats(name string) *SpanStats {
+	return gs.Spans[name]
+}
+
+// GetSpans returns a map of work span statistics
+func (gs *GoroutineStats) GetSpans() map[string]*SpanStats {
+	return maps.Clone(gs.Spans)
+}
+
+// GetTrackerOverhead returns the time the tracker spent recording this goroutine
+func (gs *GoroutineStats) GetTrackerOverhead() time.Duration {
+	return gs.Overhead
+}
+
+// GetTotalTrackerOverhead returns the time the tracker spent recording all goroutines
+func GetTotalTrackerOverhead(stats map[GoroutineId]*GoroutineStats) time.Duration {
+	var total time.Duration
+	for _, stat := range stats {
+		total += stat.Overhead
+	}
+	return total
+}
+
 // GetSelectCaseStats returns statistics for a specific select case
 func (gs *GoroutineStats) GetSelectCaseStats(caseName string) *SelectStats {
 	return gs.SelectStats[caseName]
@@ -40,6 +191,16 @@ func (gs *GoroutineStats) GetSelectStats() map[string]*SelectStats {
 
 // PrintStats prints a summary of goroutine performance statistics
 func PrintAndSaveStatsText(stats map[GoroutineId]*GoroutineStats, title string) {
+	printAndSaveSnapshotText(&Snapshot{Stats: stats}, title)
+}
+
+// SaveStatsText saves a summary of goroutine performance statistics to a text file
+func SaveStatsText(stats map[GoroutineId]*GoroutineStats, title string) {
+	saveSnapshotText(&Snapshot{Stats: stats}, title)
+}
+
+// printAndSaveSnapshotText prints a snapshot's report and saves it to a text file
+func printAndSaveSnapshotText(snap *Snapshot, title string) {
 	// Open file for writing
 	file, err := os.Create(fmt.Sprintf("%s.txt", title))
 	if err != nil {
@@ -49,223 +210,805 @@ func PrintAndSaveStatsText(stats map[GoroutineId]*GoroutineStats, title string)
 	defer file.Close()
 
 	// Create a multi-writer to write to both file and stdout
-	writer := io.MultiWriter(os.Stdout, file)
+	writeStatsText(io.MultiWriter(os.Stdout, file), snap, title)
+}
+
+// saveSnapshotText saves a snapshot's report to a text file
+func saveSnapshotText(snap *Snapshot, title string) {
+	// Open file for writing
+	file, err := os.Create(fmt.Sprintf("%s.txt", title))
+	if err != nil {
+		log.Printf("Error creating stats file: %v", err)
+		return
+	}
+	defer file.Close()
 
+	writeStatsText(file, snap, title)
+}
+
+// writeStatsText writes a summary of goroutine performance statistics to writer
+func writeStatsText(writer io.Writer, snap *Snapshot, title string) {
 	// Write title
 	fmt.Fprintln(writer, "\n"+title)
 	fmt.Fprintln(writer, strings.Repeat("=", len(title)))
+	if overhead := GetTotalTrackerOverhead(snap.Stats) + GetTotalGroupOverhead(snap.Groups); overhead > 0 {
+		fmt.Fprintf(writer, "Total Tracker Overhead: %v\n", overhead)
+	}
+	if snap.DroppedCaseNames > 0 {
+		fmt.Fprintf(writer, "Dropped Case Names: %d (hits folded into %s)\n", snap.DroppedCaseNames, OtherCaseName)
+	}
 
-	for goroutineID, stat := range stats {
+	for goroutineID, stat := range snap.Stats {
 		fmt.Fprintf(writer, "\nGoroutine %d:\n", goroutineID)
+		if stat.Group != "" {
+			fmt.Fprintf(writer, "  Group: %s\n", stat.Group)
+		}
+		if stat.SpawnSite != nil {
+			fmt.Fprintf(writer, "  Created By: Goroutine %d at %s (%s)\n", stat.ParentId, stat.SpawnSite, stat.SpawnSite.Function)
+		} else if stat.ParentId == UnknownParent {
+			fmt.Fprintf(writer, "  Created By: unknown\n")
+		}
 		fmt.Fprintf(writer, "  Lifetime: %v\n", stat.GetGoroutineLifetime())
+		if p := stat.GetPanic(); p != nil {
+			fmt.Fprintf(writer, "  Exit Reason: %s (%s)\n", stat.GetExitReason(), p.Value)
+		} else if stat.GetExitReason() != "" {
+			fmt.Fprintf(writer, "  Exit Reason: %s\n", stat.GetExitReason())
+		}
 		fmt.Fprintf(writer, "  Total Select Blocked Time: %v\n", stat.GetTotalSelectBlockedTime())
+		writeCategoriesText(writer, stat.GetBlockedCategories())
+		writeSpinText(writer, snap, stat.GetSpinStats())
+		if stat.GetTotalHandlingTime() > 0 {
+			fmt.Fprintf(writer, "  Total Handling Time: %v\n", stat.GetTotalHandlingTime())
+		}
+		fmt.Fprintf(writer, "  Working Time: %v\n", stat.GetWorkingTime())
+		fmt.Fprintf(writer, "  Unaccounted Time: %v\n", stat.GetUnaccountedTime())
+		if stat.GetTrackerOverhead() > 0 {
+			fmt.Fprintf(writer, "  Tracker Overhead: %v\n", stat.GetTrackerOverhead())
+		}
+		writeSpansText(writer, stat.GetSpans())
+		writeSelectStatsText(writer, stat.GetSelectStats())
+	}
 
-		fmt.Fprintln(writer, "  Select Case Statistics:")
-		for caseName, caseStats := range stat.GetSelectStats() {
-			fmt.Fprintf(writer, "    %s:\n", caseName)
-			fmt.Fprintf(writer, "      Hits: %d\n", caseStats.GetCaseHits())
-			fmt.Fprintf(writer, "      Total Blocked Time: %v\n", caseStats.GetCaseTime())
-			if caseStats.GetCaseHits() > 0 {
-				fmt.Fprintf(writer, "      Average Blocked Time: %v\n", caseStats.GetCaseTime()/time.Duration(caseStats.GetCaseHits()))
-				fmt.Fprintf(writer, "      90th Percentile Blocked Time: %v\n", caseStats.GetPercentile(90))
-				fmt.Fprintf(writer, "      99th Percentile Blocked Time: %v\n", caseStats.GetPercentile(99))
-			}
+	for name, group := range snap.Groups {
+		fmt.Fprintf(writer, "\nGroup %s (%d finished goroutines rolled up):\n", name, group.GetGoroutineCount())
+		fmt.Fprintf(writer, "  Total Lifetime: %v\n", group.GetTotalLifetime())
+		fmt.Fprintf(writer, "  Total Select Blocked Time: %v\n", group.GetTotalSelectBlockedTime())
+		writeCategoriesText(writer, group.GetBlockedCategories())
+		writeSpinText(writer, snap, group.GetSpinStats())
+		if group.GetTotalHandlingTime() > 0 {
+			fmt.Fprintf(writer, "  Total Handling Time: %v\n", group.GetTotalHandlingTime())
 		}
+		fmt.Fprintf(writer, "  Working Time: %v\n", group.GetWorkingTime())
+		fmt.Fprintf(writer, "  Unaccounted Time: %v\n", group.GetUnaccountedTime())
+		if group.GetTrackerOverhead() > 0 {
+			fmt.Fprintf(writer, "  Tracker Overhead: %v\n", group.GetTrackerOverhead())
+		}
+		writeSpansText(writer, group.GetSpans())
+		writeSelectStatsText(writer, group.GetSelectStats())
 	}
+
+	writeSitesText(writer, snap.Sites())
+	writeChannelsText(writer, snap.Channels)
+	writeLocksText(writer, snap.Locks)
+	writeShutdownsText(writer, snap.Shutdowns())
+	writeExitsText(writer, snap.Exits())
+	writeWarningsText(writer, snap)
 }
 
-// SaveStatsText saves a summary of goroutine performance statistics to a text file
-func SaveStatsText(stats map[GoroutineId]*GoroutineStats, title string) {
-	// Open file for writing
-	file, err := os.Create(fmt.Sprintf("%s.txt", title))
-	if err != nil {
-		log.Printf("Error creating stats file: %v", err)
+// writeExitsText writes how the goroutines of each group ended, with the
+// stacks of the panics
+func writeExitsText(writer io.Writer, exits map[string]*ExitStats) {
+	if len(exits) == 0 {
 		return
 	}
-	defer file.Close()
 
-	// Write title
-	fmt.Fprintln(file, "\n"+title)
-	fmt.Fprintln(file, strings.Repeat("=", len(title)))
+	fmt.Fprintln(writer, "\nExit Reasons:")
+	for _, name := range slices.Sorted(maps.Keys(exits)) {
+		es := exits[name]
+		reasons := make([]string, 0, len(es.Reasons))
+		for _, reason := range slices.Sorted(maps.Keys(es.Reasons)) {
+			reasons = append(reasons, fmt.Sprintf("%d %s", es.Reasons[reason], reason))
+		}
+		fmt.Fprintf(writer, "  %s: %s\n", name, strings.Join(reasons, ", "))
+		for _, p := range es.Panics {
+			fmt.Fprintf(writer, "    Goroutine %d panicked: %s\n", p.GoroutineId, p.Value)
+			for line := range strings.Lines(p.Stack) {
+				fmt.Fprintf(writer, "      %s", line)
+			}
+		}
+	}
+}
 
-	for goroutineID, stat := range stats {
-		fmt.Fprintf(file, "\nGoroutine %d:\n", goroutineID)
-		fmt.Fprintf(file, "  Lifetime: %v\n", stat.GetGoroutineLifetime())
-		fmt.Fprintf(file, "  Total Select Blocked Time: %v\n", stat.GetTotalSelectBlockedTime())
-
-		fmt.Fprintln(file, "  Select Case Statistics:")
-		for caseName, caseStats := range stat.GetSelectStats() {
-			fmt.Fprintf(file, "    %s:\n", caseName)
-			fmt.Fprintf(file, "      Hits: %d\n", caseStats.GetCaseHits())
-			fmt.Fprintf(file, "      Total Blocked Time: %v\n", caseStats.GetCaseTime())
-			if caseStats.GetCaseHits() > 0 {
-				fmt.Fprintf(file, "      Average Blocked Time: %v\n", caseStats.GetCaseTime()/time.Duration(caseStats.GetCaseHits()))
-				fmt.Fprintf(file, "      90th Percentile Blocked Time: %v\n", caseStats.GetPercentile(90))
-				fmt.Fprintf(file, "      99th Percentile Blocked Time: %v\n", caseStats.GetPercentile(99))
+// writeShutdownsText writes how long the cancelled goroutines of each group
+// took to exit
+func writeShutdownsText(writer io.Writer, shutdowns map[string]*ShutdownStats) {
+	if len(shutdowns) == 0 {
+		return
+	}
+
+	fmt.Fprintln(writer, "\nShutdown Latency:")
+	for _, name := range slices.Sorted(maps.Keys(shutdowns)) {
+		shutdown := shutdowns[name]
+		fmt.Fprintf(writer, "  %s:\n", name)
+		fmt.Fprintf(writer, "    Exits: %d, Still Running: %d\n", shutdown.Exits, shutdown.Pending)
+		if shutdown.Exits > 0 {
+			fmt.Fprintf(writer, "    Latency: average %v, p50 %v, p90 %v, p99 %v, max %v\n", shutdown.GetAverageLatency(),
+				shutdown.GetPercentile(50), shutdown.GetPercentile(90), shutdown.GetPercentile(99), shutdown.MaxLatency)
+		}
+		for _, exit := range shutdown.Slowest {
+			if exit.Pending {
+				fmt.Fprintf(writer, "    Goroutine %d: still running %v after cancellation\n", exit.GoroutineId, exit.Latency)
+			} else {
+				fmt.Fprintf(writer, "    Goroutine %d: exited %v after cancellation\n", exit.GoroutineId, exit.Latency)
 			}
 		}
 	}
 }
 
+// writeSpinText writes how often the default branch was taken, if it ever was
+func writeSpinText(writer io.Writer, snap *Snapshot, spin SpinStats) {
+	if spin.DefaultHits == 0 {
+		return
+	}
+
+	fmt.Fprintf(writer, "  Spin: %d default hits, %.0f/s, %.1f per case hit\n", spin.DefaultHits, spin.Rate, spin.Ratio)
+	if snap.IsSpinning(spin) {
+		fmt.Fprintln(writer, "  Warning: spinning on a default branch instead of blocking")
+	}
+}
+
+// writeWarningsText lists the goroutines and groups that are spinning
+func writeWarningsText(writer io.Writer, snap *Snapshot) {
+	var warnings []string
+	for _, id := range slices.Sorted(maps.Keys(snap.Stats)) {
+		if spin := snap.Stats[id].GetSpinStats(); snap.IsSpinning(spin) {
+			warnings = append(warnings, fmt.Sprintf("Goroutine %d is spinning: %.0f default hits/s, %.1f per case hit", id, spin.Rate, spin.Ratio))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(snap.Groups)) {
+		if spin := snap.Groups[name].GetSpinStats(); snap.IsSpinning(spin) {
+			warnings = append(warnings, fmt.Sprintf("Group %s is spinning: %.0f default hits/s, %.1f per case hit", name, spin.Rate, spin.Ratio))
+		}
+	}
+	if len(warnings) == 0 {
+		return
+	}
+
+	fmt.Fprintln(writer, "\nWarnings:")
+	for _, warning := range warnings {
+		fmt.Fprintf(writer, "  %s\n", warning)
+	}
+}
+
+// writeLocksText writes the statistics of every lock recorded by Mutex and RWMutex
+func writeLocksText(writer io.Writer, locks map[string]*LockStats) {
+	if len(locks) == 0 {
+		return
+	}
+
+	fmt.Fprintln(writer, "\nLocks:")
+	for _, name := range slices.Sorted(maps.Keys(locks)) {
+		lock := locks[name]
+		fmt.Fprintf(writer, "  %s:\n", name)
+		fmt.Fprintf(writer, "    Acquisitions: %d (%d read)\n", lock.GetAcquisitions(), lock.RLocks)
+		fmt.Fprintf(writer, "    Wait Time: total %v, average %v, max %v\n", lock.WaitTime, lock.GetAverageWait(), lock.MaxWait)
+		fmt.Fprintf(writer, "    Hold Time: total %v, average %v, max %v\n", lock.HoldTime, lock.GetAverageHold(), lock.MaxHold)
+	}
+}
+
+// writeChannelsText writes the statistics of every channel created with NewChan
+func writeChannelsText(writer io.Writer, channels map[string]*ChannelStats) {
+	if len(channels) == 0 {
+		return
+	}
+
+	fmt.Fprintln(writer, "\nChannels:")
+	for _, name := range slices.Sorted(maps.Keys(channels)) {
+		channel := channels[name]
+		fmt.Fprintf(writer, "  %s (capacity %d):\n", name, channel.Capacity)
+		fmt.Fprintf(writer, "    Sends: %d, Total Wait: %v, Average Wait: %v\n",
+			channel.Sends, channel.SendWaitTime, channel.GetAverageSendWait())
+		fmt.Fprintf(writer, "    Receives: %d, Total Wait: %v, Average Wait: %v\n",
+			channel.Receives, channel.RecvWaitTime, channel.GetAverageRecvWait())
+		fmt.Fprintf(writer, "    Occupancy: mean %.2f, max %d\n", channel.GetMeanOccupancy(), channel.MaxOccupancy)
+		if occupancy := channel.Occupancy; occupancy != nil {
+			fmt.Fprintf(writer, "    Fill Ratio: min %.1f%%, mean %.1f%%, max %.1f%% over %d samples\n",
+				occupancy.MinFill*100, occupancy.GetMeanFill()*100, occupancy.MaxFill*100, occupancy.Samples)
+			fmt.Fprintf(writer, "    Time At Full: %v, Time At Empty: %v\n", occupancy.TimeAtFull, occupancy.TimeAtEmpty)
+		}
+		if channel.IsClosed() {
+			fmt.Fprintf(writer, "    Closed: by goroutine %d at %s\n", channel.ClosedBy, channel.ClosedAt.Format(time.RFC3339Nano))
+		}
+	}
+}
+
+// writeSitesText writes how often each case of every select site won
+func writeSitesText(writer io.Writer, sites map[string]*SiteStats) {
+	if len(sites) == 0 {
+		return
+	}
+
+	fmt.Fprintln(writer, "\nSelect Sites:")
+	names := slices.Sorted(maps.Keys(sites))
+	for _, name := range names {
+		site := sites[name]
+		fmt.Fprintf(writer, "  %s:\n", site.Name)
+		fmt.Fprintf(writer, "    Runs: %d\n", site.Hits)
+		fmt.Fprintf(writer, "    Total Blocked Time: %v\n", site.BlockedTime)
+		fmt.Fprintf(writer, "    Fairness: %.2f\n", site.GetFairness())
+
+		cases := slices.SortedFunc(maps.Keys(site.Wins), func(a, b string) int {
+			return site.Wins[b] - site.Wins[a]
+		})
+		for _, caseName := range cases {
+			fmt.Fprintf(writer, "    %s: %d wins (%.1f%%), Blocked Time: %v\n",
+				caseName, site.Wins[caseName], site.GetWinRate(caseName)*100, site.CaseBlocked[caseName])
+		}
+	}
+}
+
+// writeSpansText writes the statistics of each work span to writer
+func writeSpansText(writer io.Writer, spans map[string]*SpanStats) {
+	if len(spans) == 0 {
+		return
+	}
+
+	fmt.Fprintln(writer, "  Work Spans:")
+	for name, spanStats := range spans {
+		fmt.Fprintf(writer, "    %s: %d spans, Total Time: %v, Self Time: %v\n",
+			name, spanStats.Count, spanStats.TotalTime, spanStats.SelfTime)
+	}
+}
+
+// writeCategoriesText writes the blocked time by category, unless none of it
+// could be classified
+func writeCategoriesText(writer io.Writer, categories BlockedCategories) {
+	if categories.GetTotal() == categories.Unclassified {
+		return
+	}
+
+	fmt.Fprintln(writer, "  Blocked Time By Category:")
+	fmt.Fprintf(writer, "    Input Starved: %v\n", categories.InputStarved)
+	fmt.Fprintf(writer, "    Output Backpressured: %v\n", categories.OutputBackpressured)
+	fmt.Fprintf(writer, "    Timer Idle: %v\n", categories.TimerIdle)
+	fmt.Fprintf(writer, "    Cancellation: %v\n", categories.Cancellation)
+	fmt.Fprintf(writer, "    Unclassified: %v\n", categories.Unclassified)
+}
+
+// writeSelectStatsText writes the statistics of each select case to writer
+func writeSelectStatsText(writer io.Writer, selectStats map[string]*SelectStats) {
+	fmt.Fprintln(writer, "  Select Case Statistics:")
+	names, depths := caseTree(selectStats)
+	for i, caseName := range names {
+		caseStats := selectStats[caseName]
+		indent := strings.Repeat("  ", depths[i])
+		label := caseName
+		if caseStats.Parent != "" && depths[i] > 0 {
+			label = strings.TrimPrefix(caseName, caseStats.Parent+NestedCaseSeparator)
+		}
+
+		fmt.Fprintf(writer, "%s    %s:\n", indent, label)
+		if caseStats.CallSite != nil {
+			fmt.Fprintf(writer, "%s      Call Site: %s (%s)\n", indent, caseStats.CallSite, caseStats.CallSite.Function)
+		}
+		fmt.Fprintf(writer, "%s      Hits: %d\n", indent, caseStats.GetCaseHits())
+		if caseStats.GetSampledHits() < caseStats.GetCaseHits() {
+			fmt.Fprintf(writer, "%s      Sampled Hits: %d (%.2f%% of hits)\n", indent, caseStats.GetSampledHits(), caseStats.GetSampleRate()*100)
+		}
+		fmt.Fprintf(writer, "%s      Total Blocked Time: %v\n", indent, caseStats.GetCaseTime())
+		if self := selfBlockedTime(selectStats, caseName); self != caseStats.GetCaseTime() {
+			fmt.Fprintf(writer, "%s      Self Blocked Time: %v\n", indent, self)
+		}
+		if caseStats.GetHandlingTime() > 0 {
+			fmt.Fprintf(writer, "%s      Total Handling Time: %v\n", indent, caseStats.GetHandlingTime())
+		}
+		if caseStats.GetCaseHits() > 0 {
+			fmt.Fprintf(writer, "%s      Average Blocked Time: %v\n", indent, caseStats.GetCaseTime()/time.Duration(caseStats.GetCaseHits()))
+			fmt.Fprintf(writer, "%s      90th Percentile Blocked Time: %v\n", indent, caseStats.GetPercentile(90))
+			fmt.Fprintf(writer, "%s      99th Percentile Blocked Time: %v\n", indent, caseStats.GetPercentile(99))
+		}
+	}
+}
+
 // JSONStats represents the complete statistics structure for JSON output
 type JSONStats struct {
-	Title      string                   `json:"title"`
-	Goroutines map[string]GoroutineJSON `json:"goroutines"`
+	Title                string                   `json:"title"`
+	Goroutines           map[string]GoroutineJSON `json:"goroutines"`
+	TotalTrackerOverhead time.Duration            `json:"total_tracker_overhead,omitempty"`
+	DroppedCaseNames     int                      `json:"dropped_case_names,omitempty"`
+	Groups               map[string]GroupJSON     `json:"groups,omitempty"`
+	Sites                map[string]SiteJSON      `json:"sites,omitempty"`
+	Channels             map[string]ChannelJSON   `json:"channels,omitempty"`
+	Locks                map[string]LockJSON      `json:"locks,omitempty"`
+	Shutdowns            map[string]ShutdownJSON  `json:"shutdowns,omitempty"`
+	Exits                map[string]ExitJSON      `json:"exits,omitempty"`
+}
+
+// ExitJSON represents how the goroutines of a group ended in JSON format
+type ExitJSON struct {
+	Reasons map[ExitReason]int64 `json:"reasons"`
+	Panics  []PanicJSON          `json:"panics,omitempty"`
+}
+
+// PanicJSON represents a panic that ended a goroutine in JSON format
+type PanicJSON struct {
+	GoroutineId GoroutineId `json:"goroutine_id"`
+	Value       string      `json:"value"`
+	Stack       string      `json:"stack"`
+}
+
+// ShutdownJSON represents how long the cancelled goroutines of a group took
+// to exit in JSON format
+type ShutdownJSON struct {
+	Exits          int64          `json:"exits"`
+	Pending        int64          `json:"pending,omitempty"`
+	AverageLatency time.Duration  `json:"average_latency"`
+	Percentile50   time.Duration  `json:"percentile_50"`
+	Percentile90   time.Duration  `json:"percentile_90"`
+	Percentile99   time.Duration  `json:"percentile_99"`
+	MaxLatency     time.Duration  `json:"max_latency"`
+	Slowest        []SlowExitJSON `json:"slowest"`
+}
+
+// SlowExitJSON represents one of the slowest goroutines to exit in JSON format
+type SlowExitJSON struct {
+	GoroutineId GoroutineId   `json:"goroutine_id"`
+	Latency     time.Duration `json:"latency"`
+	Pending     bool          `json:"pending,omitempty"`
+}
+
+// LockJSON represents the statistics of a lock recorded by Mutex and RWMutex in JSON format
+type LockJSON struct {
+	Locks         int64         `json:"locks"`
+	RLocks        int64         `json:"read_locks,omitempty"`
+	TotalWaitTime time.Duration `json:"total_wait_time"`
+	TotalHoldTime time.Duration `json:"total_hold_time"`
+	MaxWaitTime   time.Duration `json:"max_wait_time"`
+	MaxHoldTime   time.Duration `json:"max_hold_time"`
+}
+
+// ChannelJSON represents the statistics of a channel created with NewChan in JSON format
+type ChannelJSON struct {
+	Capacity          int            `json:"capacity"`
+	Sends             int64          `json:"sends"`
+	TotalSendWait     time.Duration  `json:"total_send_wait_time"`
+	Receives          int64          `json:"receives"`
+	TotalReceiveWait  time.Duration  `json:"total_receive_wait_time"`
+	OccupancySamples  int64          `json:"occupancy_samples"`
+	MeanOccupancy     float64        `json:"mean_occupancy"`
+	MaxOccupancy      int            `json:"max_occupancy"`
+	ClosedAt          *time.Time     `json:"closed_at,omitempty"`
+	ClosedByGoroutine GoroutineId    `json:"closed_by,omitempty"`
+	Occupancy         *OccupancyJSON `json:"occupancy,omitempty"`
+}
+
+// OccupancyJSON represents the sampled fill ratio of a channel in JSON format
+type OccupancyJSON struct {
+	Samples     int64            `json:"samples"`
+	MinFill     float64          `json:"min_fill"`
+	MeanFill    float64          `json:"mean_fill"`
+	MaxFill     float64          `json:"max_fill"`
+	TimeAtFull  time.Duration    `json:"time_at_full"`
+	TimeAtEmpty time.Duration    `json:"time_at_empty"`
+	Series      []OccupancyPoint `json:"series"`
+}
+
+// SiteJSON represents the win distribution of a select site in JSON format
+type SiteJSON struct {
+	Name             string                  `json:"name,omitempty"`
+	Runs             int64                   `json:"runs"`
+	TotalBlockedTime time.Duration           `json:"total_blocked_time"`
+	Fairness         float64                 `json:"fairness"`
+	Cases            map[string]SiteCaseJSON `json:"cases"`
+}
+
+// SiteCaseJSON represents one case of a select site in JSON format
+type SiteCaseJSON struct {
+	Wins             int64         `json:"wins"`
+	WinRate          float64       `json:"win_rate"`
+	TotalBlockedTime time.Duration `json:"total_blocked_time"`
 }
 
 // GoroutineJSON represents a single goroutine's statistics in JSON format
 type GoroutineJSON struct {
+	Group           string              `json:"group,omitempty"`
+	ParentId        GoroutineId         `json:"parent_id,omitempty"`
+	SpawnSite       *CallSite           `json:"spawn_site,omitempty"`
 	Lifetime        time.Duration       `json:"lifetime"`
 	TotalSelectTime time.Duration       `json:"total_select_blocked_time"`
+	TotalHandling   time.Duration       `json:"total_handling_time,omitempty"`
+	WorkingTime     time.Duration       `json:"working_time,omitempty"`
+	UnaccountedTime time.Duration       `json:"unaccounted_time"`
+	Spans           map[string]SpanJSON `json:"spans,omitempty"`
+	Categories      CategoriesJSON      `json:"blocked_categories"`
+	Spin            *SpinJSON           `json:"spin,omitempty"`
+	CancelledAt     *time.Time          `json:"cancelled_at,omitempty"`
+	ShutdownLatency time.Duration       `json:"shutdown_latency,omitempty"`
+	ExitReason      ExitReason          `json:"exit_reason,omitempty"`
+	Panic           *PanicJSON          `json:"panic,omitempty"`
 	SelectCaseStats map[string]CaseJSON `json:"select_case_statistics"`
+	TrackerOverhead time.Duration       `json:"tracker_overhead,omitempty"`
+}
+
+// GroupJSON represents the rolled up goroutines of a group in JSON format
+type GroupJSON struct {
+	Goroutines      int                 `json:"goroutines"`
+	TotalLifetime   time.Duration       `json:"total_lifetime"`
+	TotalSelectTime time.Duration       `json:"total_select_blocked_time"`
+	TotalHandling   time.Duration       `json:"total_handling_time,omitempty"`
+	WorkingTime     time.Duration       `json:"working_time,omitempty"`
+	UnaccountedTime time.Duration       `json:"unaccounted_time"`
+	Spans           map[string]SpanJSON `json:"spans,omitempty"`
+	Categories      CategoriesJSON      `json:"blocked_categories"`
+	Spin            *SpinJSON           `json:"spin,omitempty"`
+	SelectCaseStats map[string]CaseJSON `json:"select_case_statistics"`
+	TrackerOverhead time.Duration       `json:"tracker_overhead,omitempty"`
+}
+
+// SpinJSON represents how often the default branch of a select was taken in JSON format
+type SpinJSON struct {
+	DefaultHits int64   `json:"default_hits"`
+	CaseHits    int64   `json:"case_hits"`
+	Rate        float64 `json:"rate"`
+	Ratio       float64 `json:"ratio"`
+	Spinning    bool    `json:"spinning"`
+}
+
+// newSpinJSON converts spin stats into their JSON representation, nil when
+// the default branch was never taken
+func newSpinJSON(spin SpinStats, spinning bool) *SpinJSON {
+	if spin.DefaultHits == 0 {
+		return nil
+	}
+	return &SpinJSON{
+		DefaultHits: int64(spin.DefaultHits),
+		CaseHits:    int64(spin.CaseHits),
+		Rate:        spin.Rate,
+		Ratio:       spin.Ratio,
+		Spinning:    spinning,
+	}
+}
+
+// CategoriesJSON represents blocked time by category in JSON format
+type CategoriesJSON struct {
+	InputStarved        time.Duration `json:"input_starved"`
+	OutputBackpressured time.Duration `json:"output_backpressured"`
+	TimerIdle           time.Duration `json:"timer_idle"`
+	Cancellation        time.Duration `json:"cancellation"`
+	Unclassified        time.Duration `json:"unclassified"`
+}
+
+// SpanJSON represents statistics for a single work span in JSON format
+type SpanJSON struct {
+	Count     int64         `json:"count"`
+	TotalTime time.Duration `json:"total_time"`
+	SelfTime  time.Duration `json:"self_time"`
 }
 
 // CaseJSON represents statistics for a single select case in JSON format
 type CaseJSON struct {
+	Hits             int64                 `json:"hits"`
+	Parent           string                `json:"parent,omitempty"`
+	Site             string                `json:"site,omitempty"`
+	CallSite         *CallSite             `json:"call_site,omitempty"`
+	Kind             CaseKind              `json:"kind,omitempty"`
+	TotalBlockedTime time.Duration         `json:"total_blocked_time"`
+	SelfBlockedTime  time.Duration         `json:"self_blocked_time,omitempty"`
+	TotalHandling    time.Duration         `json:"total_handling_time,omitempty"`
+	AvgBlockedTime   time.Duration         `json:"average_blocked_time,omitempty"`
+	Percentile90     time.Duration         `json:"percentile_90,omitempty"`
+	Percentile99     time.Duration         `json:"percentile_99,omitempty"`
+	Windows          map[string]WindowJSON `json:"windows,omitempty"`
+	SampledHits      int64                 `json:"sampled_hits,omitempty"`
+	SampleRate       float64               `json:"sample_rate,omitempty"`
+}
+
+// WindowJSON represents the statistics of a select case over a sliding window
+type WindowJSON struct {
 	Hits             int64         `json:"hits"`
 	TotalBlockedTime time.Duration `json:"total_blocked_time"`
-	AvgBlockedTime   time.Duration `json:"average_blocked_time,omitempty"`
-	Percentile90     time.Duration `json:"percentile_90,omitempty"`
-	Percentile99     time.Duration `json:"percentile_99,omitempty"`
+	Percentile50     time.Duration `json:"percentile_50"`
+	Percentile99     time.Duration `json:"percentile_99"`
 }
 
-// PrintAndSaveStatsJSON prints and saves goroutine performance statistics as JSON
-func PrintAndSaveStatsJSON(stats map[GoroutineId]*GoroutineStats, title string) {
-	// Create JSON structure
-	jsonStats := JSONStats{
-		Title:      title,
-		Goroutines: make(map[string]GoroutineJSON),
+// JSON converts the snapshot into its JSON representation
+func (snap *Snapshot) JSON(title string) JSONStats {
+	jsonStats := NewJSONStats(snap.Stats, title)
+	jsonStats.DroppedCaseNames = snap.DroppedCaseNames
+	for id, stat := range snap.Stats {
+		if spin := jsonStats.Goroutines[fmt.Sprintf("%d", id)].Spin; spin != nil {
+			spin.Spinning = snap.IsSpinning(stat.GetSpinStats())
+		}
 	}
 
-	// Populate the structure
-	for goroutineID, stat := range stats {
-		goroutineJSON := GoroutineJSON{
-			Lifetime:        stat.GetGoroutineLifetime(),
-			TotalSelectTime: stat.GetTotalSelectBlockedTime(),
-			SelectCaseStats: make(map[string]CaseJSON),
+	if len(snap.Groups) > 0 {
+		jsonStats.Groups = make(map[string]GroupJSON, len(snap.Groups))
+		for name, group := range snap.Groups {
+			groupJSON := GroupJSON{
+				Goroutines:      group.GetGoroutineCount(),
+				TotalLifetime:   group.GetTotalLifetime(),
+				TotalSelectTime: group.GetTotalSelectBlockedTime(),
+				TotalHandling:   group.GetTotalHandlingTime(),
+				WorkingTime:     group.GetWorkingTime(),
+				UnaccountedTime: group.GetUnaccountedTime(),
+				Spans:           newSpansJSON(group.GetSpans()),
+				Categories:      CategoriesJSON(group.GetBlockedCategories()),
+				Spin:            newSpinJSON(group.GetSpinStats(), snap.IsSpinning(group.GetSpinStats())),
+				SelectCaseStats: newCasesJSON(group.GetSelectStats()),
+				TrackerOverhead: group.GetTrackerOverhead(),
+			}
+			jsonStats.Groups[name] = groupJSON
+			jsonStats.TotalTrackerOverhead += group.GetTrackerOverhead()
 		}
+	}
 
-		for caseName, caseStats := range stat.GetSelectStats() {
-			caseJSON := CaseJSON{
-				Hits:             int64(caseStats.GetCaseHits()),
-				TotalBlockedTime: caseStats.GetCaseTime(),
+	if sites := snap.Sites(); len(sites) > 0 {
+		jsonStats.Sites = make(map[string]SiteJSON, len(sites))
+		for name, site := range sites {
+			siteJSON := SiteJSON{
+				Runs:             int64(site.Hits),
+				TotalBlockedTime: site.BlockedTime,
+				Fairness:         site.GetFairness(),
+				Cases:            make(map[string]SiteCaseJSON, len(site.Wins)),
+			}
+			if site.Name != name {
+				siteJSON.Name = site.Name
 			}
-			if caseStats.GetCaseHits() > 0 {
-				caseJSON.AvgBlockedTime = caseStats.GetCaseTime() / time.Duration(caseStats.GetCaseHits())
-				caseJSON.Percentile90 = caseStats.GetPercentile(90)
-				caseJSON.Percentile99 = caseStats.GetPercentile(99)
+			for caseName, wins := range site.Wins {
+				siteJSON.Cases[caseName] = SiteCaseJSON{
+					Wins:             int64(wins),
+					WinRate:          site.GetWinRate(caseName),
+					TotalBlockedTime: site.CaseBlocked[caseName],
+				}
 			}
-			goroutineJSON.SelectCaseStats[caseName] = caseJSON
+			jsonStats.Sites[name] = siteJSON
 		}
-
-		jsonStats.Goroutines[fmt.Sprintf("%d", goroutineID)] = goroutineJSON
 	}
 
-	// Marshal to JSON
-	jsonData, err := json.MarshalIndent(jsonStats, "", "  ")
-	if err != nil {
-		log.Printf("Error marshaling stats to JSON: %v", err)
-		return
+	if len(snap.Channels) > 0 {
+		jsonStats.Channels = make(map[string]ChannelJSON, len(snap.Channels))
+		for name, channel := range snap.Channels {
+			channelJSON := ChannelJSON{
+				Capacity:          channel.Capacity,
+				Sends:             int64(channel.Sends),
+				TotalSendWait:     channel.SendWaitTime,
+				Receives:          int64(channel.Receives),
+				TotalReceiveWait:  channel.RecvWaitTime,
+				OccupancySamples:  int64(channel.OccupancySamples),
+				MeanOccupancy:     channel.GetMeanOccupancy(),
+				MaxOccupancy:      channel.MaxOccupancy,
+				ClosedByGoroutine: channel.ClosedBy,
+			}
+			if channel.IsClosed() {
+				closedAt := channel.ClosedAt
+				channelJSON.ClosedAt = &closedAt
+			}
+			if occupancy := channel.Occupancy; occupancy != nil {
+				channelJSON.Occupancy = &OccupancyJSON{
+					Samples:     int64(occupancy.Samples),
+					MinFill:     occupancy.MinFill,
+					MeanFill:    occupancy.GetMeanFill(),
+					MaxFill:     occupancy.MaxFill,
+					TimeAtFull:  occupancy.TimeAtFull,
+					TimeAtEmpty: occupancy.TimeAtEmpty,
+					Series:      occupancy.Series,
+				}
+			}
+			jsonStats.Channels[name] = channelJSON
+		}
 	}
 
-	// Save to file
-	filePath := fmt.Sprintf("%s.json", title)
-	if err := os.WriteFile(filePath, jsonData, 0644); err != nil {
-		log.Printf("Error writing JSON stats file: %v", err)
-		return
+	if len(snap.Locks) > 0 {
+		jsonStats.Locks = make(map[string]LockJSON, len(snap.Locks))
+		for name, lock := range snap.Locks {
+			jsonStats.Locks[name] = LockJSON{
+				Locks:         int64(lock.Locks),
+				RLocks:        int64(lock.RLocks),
+				TotalWaitTime: lock.WaitTime,
+				TotalHoldTime: lock.HoldTime,
+				MaxWaitTime:   lock.MaxWait,
+				MaxHoldTime:   lock.MaxHold,
+			}
+		}
 	}
 
-	// Print to stdout
-	fmt.Println(string(jsonData))
-}
+	if exits := snap.Exits(); len(exits) > 0 {
+		jsonStats.Exits = make(map[string]ExitJSON, len(exits))
+		for name, es := range exits {
+			exitJSON := ExitJSON{Reasons: make(map[ExitReason]int64, len(es.Reasons))}
+			for reason, count := range es.Reasons {
+				exitJSON.Reasons[reason] = int64(count)
+			}
+			for _, p := range es.Panics {
+				exitJSON.Panics = append(exitJSON.Panics, PanicJSON(p))
+			}
+			jsonStats.Exits[name] = exitJSON
+		}
+	}
 
-// SaveStats saves goroutine performance statistics to a JSON file in a directory named after the stage
-func SaveStatsJSON(stats map[GoroutineId]*GoroutineStats, title string) error {
-	file, err := os.Create(fmt.Sprintf("%s.json", title))
-	if err != nil {
-		return err
+	if shutdowns := snap.Shutdowns(); len(shutdowns) > 0 {
+		jsonStats.Shutdowns = make(map[string]ShutdownJSON, len(shutdowns))
+		for name, shutdown := range shutdowns {
+			slowest := make([]SlowExitJSON, len(shutdown.Slowest))
+			for i, exit := range shutdown.Slowest {
+				slowest[i] = SlowExitJSON(exit)
+			}
+			jsonStats.Shutdowns[name] = ShutdownJSON{
+				Exits:          int64(shutdown.Exits),
+				Pending:        int64(shutdown.Pending),
+				AverageLatency: shutdown.GetAverageLatency(),
+				Percentile50:   shutdown.GetPercentile(50),
+				Percentile90:   shutdown.GetPercentile(90),
+				Percentile99:   shutdown.GetPercentile(99),
+				MaxLatency:     shutdown.MaxLatency,
+				Slowest:        slowest,
+			}
+		}
 	}
-	defer file.Close()
 
-	// Create JSON structure
+	return jsonStats
+}
+
+// NewJSONStats converts goroutine statistics into their JSON representation
+func NewJSONStats(stats map[GoroutineId]*GoroutineStats, title string) JSONStats {
 	jsonStats := JSONStats{
-		Title:      title,
-		Goroutines: make(map[string]GoroutineJSON),
+		Title:                title,
+		Goroutines:           make(map[string]GoroutineJSON),
+		TotalTrackerOverhead: GetTotalTrackerOverhead(stats),
 	}
 
-	// Convert stats to JSON structure
 	for goroutineID, stat := range stats {
 		goroutineJSON := GoroutineJSON{
+			Group:           stat.Group,
+			ParentId:        stat.ParentId,
+			SpawnSite:       stat.SpawnSite,
 			Lifetime:        stat.GetGoroutineLifetime(),
 			TotalSelectTime: stat.GetTotalSelectBlockedTime(),
-			SelectCaseStats: make(map[string]CaseJSON),
+			TotalHandling:   stat.GetTotalHandlingTime(),
+			WorkingTime:     stat.GetWorkingTime(),
+			UnaccountedTime: stat.GetUnaccountedTime(),
+			Spans:           newSpansJSON(stat.GetSpans()),
+			Categories:      CategoriesJSON(stat.GetBlockedCategories()),
+			Spin:            newSpinJSON(stat.GetSpinStats(), stat.GetSpinStats().IsSpinning(0, 0)),
+			ShutdownLatency: stat.GetShutdownLatency(),
+			ExitReason:      stat.GetExitReason(),
+			SelectCaseStats: newCasesJSON(stat.GetSelectStats()),
+			TrackerOverhead: stat.GetTrackerOverhead(),
+		}
+		if stat.WasCancelled() {
+			goroutineJSON.CancelledAt = &stat.CancelledAt
+		}
+		if p := stat.GetPanic(); p != nil {
+			panicJSON := PanicJSON(*p)
+			goroutineJSON.Panic = &panicJSON
 		}
 
-		// Convert select case statistics
-		for caseName, caseStats := range stat.GetSelectStats() {
-			caseJSON := CaseJSON{
-				Hits:             int64(caseStats.GetCaseHits()),
-				TotalBlockedTime: caseStats.GetCaseTime(),
-			}
+		jsonStats.Goroutines[fmt.Sprintf("%d", goroutineID)] = goroutineJSON
+	}
 
-			if caseStats.GetCaseHits() > 0 {
-				caseJSON.AvgBlockedTime = caseStats.GetCaseTime() / time.Duration(caseStats.GetCaseHits())
-				caseJSON.Percentile90 = caseStats.GetPercentile(90)
-				caseJSON.Percentile99 = caseStats.GetPercentile(99)
-			}
+	return jsonStats
+}
 
-			goroutineJSON.SelectCaseStats[caseName] = caseJSON
+// newSpansJSON converts work span statistics into their JSON representation
+func newSpansJSON(spans map[string]*SpanStats) map[string]SpanJSON {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	spansJSON := make(map[string]SpanJSON, len(spans))
+	for name, spanStats := range spans {
+		spansJSON[name] = SpanJSON{
+			Count:     int64(spanStats.Count),
+			TotalTime: spanStats.TotalTime,
+			SelfTime:  spanStats.SelfTime,
 		}
+	}
+	return spansJSON
+}
 
-		jsonStats.Goroutines[fmt.Sprintf("%d", goroutineID)] = goroutineJSON
+// newCasesJSON converts the select cases of a goroutine or group into their
+// JSON representation
+func newCasesJSON(selectStats map[string]*SelectStats) map[string]CaseJSON {
+	casesJSON := make(map[string]CaseJSON, len(selectStats))
+	for caseName, caseStats := range selectStats {
+		caseJSON := newCaseJSON(caseStats)
+		if self := selfBlockedTime(selectStats, caseName); self != caseJSON.TotalBlockedTime {
+			caseJSON.SelfBlockedTime = self
+		}
+		casesJSON[caseName] = caseJSON
 	}
+	return casesJSON
+}
 
-	jsonData, err := json.MarshalIndent(jsonStats, "", "  ")
-	if err != nil {
-		return fmt.Errorf("error marshaling stats to JSON: %w", err)
+// newCaseJSON converts a select case's statistics into its JSON representation
+func newCaseJSON(caseStats *SelectStats) CaseJSON {
+	caseJSON := CaseJSON{
+		Parent:           caseStats.Parent,
+		Site:             caseStats.Site,
+		CallSite:         caseStats.CallSite,
+		Kind:             caseStats.Kind,
+		Hits:             int64(caseStats.GetCaseHits()),
+		TotalBlockedTime: caseStats.GetCaseTime(),
+		TotalHandling:    caseStats.GetHandlingTime(),
 	}
 
-	if err := os.WriteFile(file.Name(), jsonData, 0644); err != nil {
-		return fmt.Errorf("error writing JSON stats file: %w", err)
+	if caseStats.GetCaseHits() > 0 {
+		caseJSON.AvgBlockedTime = caseStats.GetCaseTime() / time.Duration(caseStats.GetCaseHits())
+		caseJSON.Percentile90 = caseStats.GetPercentile(90)
+		caseJSON.Percentile99 = caseStats.GetPercentile(99)
 	}
 
-	return nil
+	if caseStats.GetSampledHits() < caseStats.GetCaseHits() {
+		caseJSON.SampledHits = int64(caseStats.GetSampledHits())
+		caseJSON.SampleRate = caseStats.GetSampleRate()
+	}
+
+	if windows := caseStats.GetWindows(); len(windows) > 0 {
+		caseJSON.Windows = make(map[string]WindowJSON, len(windows))
+		for _, window := range windows {
+			caseJSON.Windows[formatWindow(window.Window)] = WindowJSON{
+				Hits:             int64(window.Hits),
+				TotalBlockedTime: window.BlockedTime,
+				Percentile50:     window.Percentile50,
+				Percentile99:     window.Percentile99,
+			}
+		}
+	}
+
+	return caseJSON
+}
+
+// PrintAndSaveStatsJSON prints and saves goroutine performance statistics as JSON
+func PrintAndSaveStatsJSON(stats map[GoroutineId]*GoroutineStats, title string) {
+	printAndSaveSnapshotJSON(&Snapshot{Stats: stats}, title)
+}
+
+// SaveStats saves goroutine performance statistics to a JSON file in a directory named after the stage
+func SaveStatsJSON(stats map[GoroutineId]*GoroutineStats, title string) error {
+	return saveSnapshotJSON(&Snapshot{Stats: stats}, title)
 }
 
 // PrintStatsJSON prints goroutine performance statistics as JSON to stdout
 func PrintStatsJSON(stats map[GoroutineId]*GoroutineStats, title string) {
-	// Create JSON structure
-	jsonStats := JSONStats{
-		Title:      title,
-		Goroutines: make(map[string]GoroutineJSON),
+	printSnapshotJSON(&Snapshot{Stats: stats}, title)
+}
+
+// printAndSaveSnapshotJSON prints a snapshot as JSON and saves it to a file
+func printAndSaveSnapshotJSON(snap *Snapshot, title string) {
+	jsonData, err := json.MarshalIndent(snap.JSON(title), "", "  ")
+	if err != nil {
+		log.Printf("Error marshaling stats to JSON: %v", err)
+		return
 	}
 
-	// Populate the structure
-	for goroutineID, stat := range stats {
-		goroutineJSON := GoroutineJSON{
-			Lifetime:        stat.GetGoroutineLifetime(),
-			TotalSelectTime: stat.GetTotalSelectBlockedTime(),
-			SelectCaseStats: make(map[string]CaseJSON),
-		}
+	// Save to file
+	filePath := fmt.Sprintf("%s.json", title)
+	if err := os.WriteFile(filePath, jsonData, 0644); err != nil {
+		log.Printf("Error writing JSON stats file: %v", err)
+		return
+	}
 
-		for caseName, caseStats := range stat.GetSelectStats() {
-			caseJSON := CaseJSON{
-				Hits:             int64(caseStats.GetCaseHits()),
-				TotalBlockedTime: caseStats.GetCaseTime(),
-			}
-			if caseStats.GetCaseHits() > 0 {
-				caseJSON.AvgBlockedTime = caseStats.GetCaseTime() / time.Duration(caseStats.GetCaseHits())
-				caseJSON.Percentile90 = caseStats.GetPercentile(90)
-				caseJSON.Percentile99 = caseStats.GetPercentile(99)
-			}
-			goroutineJSON.SelectCaseStats[caseName] = caseJSON
-		}
+	// Print to stdout
+	fmt.Println(string(jsonData))
+}
 
-		jsonStats.Goroutines[fmt.Sprintf("%d", goroutineID)] = goroutineJSON
+// saveSnapshotJSON saves a snapshot to a JSON file
+func saveSnapshotJSON(snap *Snapshot, title string) error {
+	jsonData, err := json.MarshalIndent(snap.JSON(title), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling stats to JSON: %w", err)
 	}
 
-	// Marshal to JSON and print to stdout
-	jsonData, err := json.MarshalIndent(jsonStats, "", "  ")
+	if err := os.WriteFile(fmt.Sprintf("%s.json", title), jsonData, 0644); err != nil {
+		return fmt.Errorf("error writing JSON stats file: %w", err)
+	}
+
+	return nil
+}
+
+// printSnapshotJSON prints a snapshot as JSON to stdout
+func printSnapshotJSON(snap *Snapshot, title string) {
+	jsonData, err := json.MarshalIndent(snap.JSON(title), "", "  ")
 	if err != nil {
 		log.Printf("Error marshaling stats to JSON: %v", err)
 		return
@@ -276,26 +1019,7 @@ func PrintStatsJSON(stats map[GoroutineId]*GoroutineStats, title string) {
 
 // PrintStatsText prints a summary of goroutine performance statistics to stdout
 func PrintStatsText(stats map[GoroutineId]*GoroutineStats, title string) {
-	fmt.Println("\n" + title)
-	fmt.Println(strings.Repeat("=", len(title)))
-
-	for goroutineID, stat := range stats {
-		fmt.Fprintf(os.Stdout, "\nGoroutine %d:\n", goroutineID)
-		fmt.Fprintf(os.Stdout, "  Lifetime: %v\n", stat.GetGoroutineLifetime())
-		fmt.Fprintf(os.Stdout, "  Total Select Blocked Time: %v\n", stat.GetTotalSelectBlockedTime())
-
-		fmt.Fprintln(os.Stdout, "  Select Case Statistics:")
-		for caseName, caseStats := range stat.GetSelectStats() {
-			fmt.Fprintf(os.Stdout, "    %s:\n", caseName)
-			fmt.Fprintf(os.Stdout, "      Hits: %d\n", caseStats.GetCaseHits())
-			fmt.Fprintf(os.Stdout, "      Total Blocked Time: %v\n", caseStats.GetCaseTime())
-			if caseStats.GetCaseHits() > 0 {
-				fmt.Fprintf(os.Stdout, "      Average Blocked Time: %v\n", caseStats.GetCaseTime()/time.Duration(caseStats.GetCaseHits()))
-				fmt.Fprintf(os.Stdout, "      90th Percentile Blocked Time: %v\n", caseStats.GetPercentile(90))
-				fmt.Fprintf(os.Stdout, "      99th Percentile Blocked Time: %v\n", caseStats.GetPercentile(99))
-			}
-		}
-	}
+	writeStatsText(os.Stdout, &Snapshot{Stats: stats}, title)
 }
 
 var buckets = []time.Duration{
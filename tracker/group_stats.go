@@ -0,0 +1,157 @@
+package tracker
+
+import (
+	"maps"
+	"math/rand/v2"
+	"time"
+)
+
+// defaultGroupLatencySamples bounds the latencies kept per group case when
+// MaxLatencySamples is unlimited, so rolled up groups use flat memory
+const defaultGroupLatencySamples = 1024
+
+// GetGoroutineCount returns how many finished goroutines were rolled up
+func (g *GroupStats) GetGoroutineCount() int {
+	return g.Goroutines
+}
+
+// GetTotalLifetime returns the sum of the rolled up goroutines' lifetimes
+func (g *GroupStats) GetTotalLifetime() time.Duration {
+	return g.Lifetime
+}
+
+// GetTotalSelectBlockedTime returns the time the rolled up goroutines spent in select cases
+func (g *GroupStats) GetTotalSelectBlockedTime() time.Duration {
+	return totalBlockedTime(g.SelectStats)
+}
+
+// GetSelfBlockedTime returns a case's blocked time without the time of the
+// cases nested in it
+func (g *GroupStats) GetSelfBlockedTime(caseName string) time.Duration {
+	return selfBlockedTime(g.SelectStats, caseName)
+}
+
+// GetTotalHandlingTime returns the time the rolled up goroutines spent handling select cases
+func (g *GroupStats) GetTotalHandlingTime() time.Duration {
+	var total time.Duration
+	for _, stats := range g.SelectStats {
+		total += stats.HandlingTime
+	}
+	return total
+}
+
+// GetWorkingTime returns the time the rolled up goroutines spent working
+func (g *GroupStats) GetWorkingTime() time.Duration {
+	return g.WorkingTime
+}
+
+// GetUnaccountedTime returns the part of the rolled up lifetimes that was
+// neither blocked nor working
+func (g *GroupStats) GetUnaccountedTime() time.Duration {
+	return max(g.Lifetime-g.GetTotalSelectBlockedTime()-g.WorkingTime, 0)
+}
+
+// GetSpans returns a map of the aggregated work span statistics
+func (g *GroupStats) GetSpans() map[string]*SpanStats {
+	return maps.Clone(g.Spans)
+}
+
+// GetTrackerOverhead returns the time the tracker spent recording the rolled up goroutines
+func (g *GroupStats) GetTrackerOverhead() time.Duration {
+	return g.Overhead
+}
+
+// GetSelectCaseStats returns the aggregated statistics of a select case
+func (g *GroupStats) GetSelectCaseStats(caseName string) *SelectStats {
+	return g.SelectStats[caseName]
+}
+
+// GetSelectStats returns a map of the aggregated select case statistics
+func (g *GroupStats) GetSelectStats() map[string]*SelectStats {
+	return maps.Clone(g.SelectStats)
+}
+
+// GetTotalGroupOverhead returns the time the tracker spent recording all rolled up goroutines
+func GetTotalGroupOverhead(groups map[string]*GroupStats) time.Duration {
+	var total time.Duration
+	for _, group := range groups {
+		total += group.Overhead
+	}
+	return total
+}
+
+// mergeSpans adds the span statistics of src to dst, returning dst
+func mergeSpans(dst, src map[string]*SpanStats) map[string]*SpanStats {
+	for name, spanStats := range src {
+		if dst == nil {
+			dst = make(map[string]*SpanStats)
+		}
+		target, exists := dst[name]
+		if !exists {
+			target = &SpanStats{}
+			dst[name] = target
+		}
+		target.Count += spanStats.Count
+		target.TotalTime += spanStats.TotalTime
+		target.SelfTime += spanStats.SelfTime
+	}
+	return dst
+}
+
+// merge adds the measurements of another select case. Latencies are fed
+// through the reservoir, so percentiles of merged cases are estimates.
+func (s *SelectStats) merge(other *SelectStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := s.SampledHits
+	for _, latency := range other.latencies {
+		if s.maxLatencies == 0 || len(s.latencies) < s.maxLatencies {
+			s.latencies = append(s.latencies, latency)
+		} else if i := rand.IntN(seen + 1); i < s.maxLatencies {
+			s.latencies[i] = latency
+		}
+		seen++
+	}
+
+	s.BlockedCaseTime += other.BlockedCaseTime
+	s.HandlingTime += other.HandlingTime
+	s.CaseHits += other.CaseHits
+	s.SampledHits += other.SampledHits
+
+	if s.window != nil && other.window != nil {
+		s.window.merge(other.window)
+	}
+}
+
+// merge adds the slots of another window with the same layout, stale slots
+// are skipped
+func (w *rollingWindow) merge(other *rollingWindow) {
+	if w.width != other.width {
+		return
+	}
+
+	for i, src := range other.slots {
+		if src == nil {
+			continue
+		}
+		if w.slots == nil {
+			w.slots = make([]*windowSlot, windowSlots)
+		}
+		dst := w.slots[i]
+		switch {
+		case dst == nil:
+			s := *src
+			w.slots[i] = &s
+		case src.epoch < dst.epoch:
+		case src.epoch > dst.epoch:
+			*dst = *src
+		default:
+			dst.hits += src.hits
+			dst.blocked += src.blocked
+			for b, count := range src.bins {
+				dst.bins[b] += count
+			}
+		}
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !idlespy_off
+
+package tracker
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ServeHTTP responds with a JSON snapshot of the current statistics
+func (gm *GoroutineManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snap := gm.Snapshot()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(snap.JSON("live")); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ListenAndServe serves live snapshots on addr, it blocks like
+// http.ListenAndServe until Done is called
+func (gm *GoroutineManager) ListenAndServe(addr string) error {
+	server, listener, err := gm.listen(addr)
+	if err != nil {
+		return err
+	}
+
+	return serve(server, listener)
+}
@@ -0,0 +1,67 @@
+//go:build !idlespy_off
+
+package tracker
+
+import "log"
+
+// SetCaseKind declares what a select case waits for, so its blocked time is
+// classified into the matching category. Cases recorded with a site or a
+// parent are looked up by their reported name first, then by their own name.
+// Channels created with NewChan set the kinds of their cases themselves. At
+// most MaxCaseNames kinds are kept, further names are ignored.
+func (gm *GoroutineManager) SetCaseKind(caseName string, kind CaseKind) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if _, exists := gm.caseKinds[caseName]; !exists && gm.caseMapFullLocked(len(gm.caseKinds)) {
+		log.Printf("IdleSpy: ignoring kind of select case %q, MaxCaseNames reached", caseName)
+		return
+	}
+	if gm.caseKinds == nil {
+		gm.caseKinds = make(map[string]CaseKind)
+	}
+	gm.caseKinds[caseName] = kind
+
+	for _, stats := range gm.Stats {
+		setKind(stats.SelectStats, caseName, kind)
+	}
+	for _, group := range gm.groups {
+		setKind(group.SelectStats, caseName, kind)
+	}
+}
+
+// caseKindLocked returns the kind set for caseName, KindDefault for
+// DefaultCaseName. Caller must hold gm.mu.
+func (gm *GoroutineManager) caseKindLocked(caseName string) CaseKind {
+	if kind, exists := gm.caseKinds[caseName]; exists {
+		return kind
+	}
+	if caseName == DefaultCaseName {
+		return KindDefault
+	}
+	return ""
+}
+
+// defaultCaseKindLocked sets the kind of a case unless one was set already
+// or MaxCaseNames kinds are kept. Caller must hold gm.mu.
+func (gm *GoroutineManager) defaultCaseKindLocked(caseName string, kind CaseKind) {
+	if _, exists := gm.caseKinds[caseName]; exists || gm.caseMapFullLocked(len(gm.caseKinds)) {
+		return
+	}
+	if gm.caseKinds == nil {
+		gm.caseKinds = make(map[string]CaseKind)
+	}
+	gm.caseKinds[caseName] = kind
+}
+
+// setKind updates the kind of the cases already recorded under caseName,
+// either as their reported name or as their own name under a site or parent
+func setKind(selectStats map[string]*SelectStats, caseName string, kind CaseKind) {
+	for name, caseStats := range selectStats {
+		if name == caseName ||
+			(caseStats.Site != "" && name == caseStats.Site+SiteCaseSeparator+caseName) ||
+			(caseStats.Parent != "" && name == caseStats.Parent+NestedCaseSeparator+caseName) {
+			caseStats.Kind = kind
+		}
+	}
+}
@@ -0,0 +1,263 @@
+//go:build !idlespy_off
+
+package tracker
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Lock locks the mutex, recording how long it waited
+func (m *Mutex) Lock() {
+	if m.Manager == nil || !m.Manager.Enabled {
+		m.mu.Lock()
+		return
+	}
+	m.lock(m.Manager.callerID())
+}
+
+// LockFrom is Lock for the tracked goroutine id, it saves looking up the
+// calling goroutine on every acquisition
+func (m *Mutex) LockFrom(id GoroutineId) {
+	if m.Manager == nil || !m.Manager.Enabled {
+		m.mu.Lock()
+		return
+	}
+	m.lock(id)
+}
+
+func (m *Mutex) lock(id GoroutineId) {
+	start := time.Now()
+	m.mu.Lock()
+	now := time.Now()
+	m.hold = lockHold{id: id, acquired: now, wait: now.Sub(start)}
+}
+
+// TryLock tries to lock the mutex without waiting and reports whether it succeeded
+func (m *Mutex) TryLock() bool {
+	if !m.mu.TryLock() {
+		return false
+	}
+	if m.Manager != nil && m.Manager.Enabled {
+		m.hold = lockHold{id: m.Manager.callerID(), acquired: time.Now()}
+	}
+	return true
+}
+
+// Unlock unlocks the mutex, recording the acquisition with how long the lock was held
+func (m *Mutex) Unlock() {
+	hold := m.hold
+	m.hold = lockHold{}
+	released := time.Now()
+	m.mu.Unlock()
+
+	if !hold.acquired.IsZero() {
+		record := m.Manager.lockRecord(&m.record, m.Name)
+		m.Manager.trackLock(record, m.Name, LockCaseSuffix, hold, released.Sub(hold.acquired))
+	}
+}
+
+// Lock locks the mutex for writing, recording how long it waited
+func (rw *RWMutex) Lock() {
+	if rw.Manager == nil || !rw.Manager.Enabled {
+		rw.mu.Lock()
+		return
+	}
+	rw.lock(rw.Manager.callerID())
+}
+
+// LockFrom is Lock for the tracked goroutine id, it saves looking up the
+// calling goroutine on every acquisition
+func (rw *RWMutex) LockFrom(id GoroutineId) {
+	if rw.Manager == nil || !rw.Manager.Enabled {
+		rw.mu.Lock()
+		return
+	}
+	rw.lock(id)
+}
+
+func (rw *RWMutex) lock(id GoroutineId) {
+	start := time.Now()
+	rw.mu.Lock()
+	now := time.Now()
+	rw.hold = lockHold{id: id, acquired: now, wait: now.Sub(start)}
+}
+
+// TryLock tries to lock the mutex for writing without waiting and reports
+// whether it succeeded
+func (rw *RWMutex) TryLock() bool {
+	if !rw.mu.TryLock() {
+		return false
+	}
+	if rw.Manager != nil && rw.Manager.Enabled {
+		rw.hold = lockHold{id: rw.Manager.callerID(), acquired: time.Now()}
+	}
+	return true
+}
+
+// Unlock unlocks the mutex for writing, recording the acquisition with how
+// long the lock was held
+func (rw *RWMutex) Unlock() {
+	hold := rw.hold
+	rw.hold = lockHold{}
+	released := time.Now()
+	rw.mu.Unlock()
+
+	if !hold.acquired.IsZero() {
+		record := rw.Manager.lockRecord(&rw.record, rw.Name)
+		rw.Manager.trackLock(record, rw.Name, LockCaseSuffix, hold, released.Sub(hold.acquired))
+	}
+}
+
+// RLock locks the mutex for reading, recording how long it waited
+func (rw *RWMutex) RLock() {
+	if rw.Manager == nil || !rw.Manager.Enabled {
+		rw.mu.RLock()
+		return
+	}
+	rw.rlock(rw.Manager.callerID())
+}
+
+// RLockFrom is RLock for the tracked goroutine id, it saves looking up the
+// calling goroutine on every acquisition
+func (rw *RWMutex) RLockFrom(id GoroutineId) {
+	if rw.Manager == nil || !rw.Manager.Enabled {
+		rw.mu.RLock()
+		return
+	}
+	rw.rlock(id)
+}
+
+func (rw *RWMutex) rlock(id GoroutineId) {
+	start := time.Now()
+	rw.mu.RLock()
+	rw.addReader(id, start, time.Now())
+}
+
+// TryRLock tries to lock the mutex for reading without waiting and reports
+// whether it succeeded
+func (rw *RWMutex) TryRLock() bool {
+	if !rw.mu.TryRLock() {
+		return false
+	}
+	if rw.Manager != nil && rw.Manager.Enabled {
+		now := time.Now()
+		rw.addReader(rw.Manager.callerID(), now, now)
+	}
+	return true
+}
+
+// RUnlock undoes a single RLock, recording the oldest read hold still open
+// with how long it was held. Read locks may be released by any goroutine:
+// the total hold time is exact, while single holds are approximate when read
+// locks are released in another order than they were taken.
+func (rw *RWMutex) RUnlock() {
+	if rw.Manager == nil || !rw.Manager.Enabled {
+		rw.mu.RUnlock()
+		return
+	}
+
+	released := time.Now()
+	rw.readersMu.Lock()
+	var hold lockHold
+	if len(rw.readers) > 0 {
+		hold = rw.readers[0]
+		rw.readers[0] = lockHold{}
+		rw.readers = rw.readers[1:]
+	}
+	rw.readersMu.Unlock()
+	rw.mu.RUnlock()
+
+	if !hold.acquired.IsZero() {
+		record := rw.Manager.lockRecord(&rw.record, rw.Name)
+		rw.Manager.trackLock(record, rw.Name, RLockCaseSuffix, hold, released.Sub(hold.acquired))
+	}
+}
+
+// addReader records a read lock taken by the goroutine id at acquired after
+// waiting since start
+func (rw *RWMutex) addReader(id GoroutineId, start, acquired time.Time) {
+	rw.readersMu.Lock()
+	defer rw.readersMu.Unlock()
+
+	rw.readers = append(rw.readers, lockHold{id: id, acquired: acquired, wait: acquired.Sub(start)})
+}
+
+// lockRecord returns the record cached in cached, looking it up by name on
+// first use
+func (gm *GoroutineManager) lockRecord(cached *atomic.Pointer[lockRecord], name string) *lockRecord {
+	if record := cached.Load(); record != nil {
+		return record
+	}
+
+	gm.mu.Lock()
+	record, exists := gm.locks[name]
+	if !exists {
+		if gm.locks == nil {
+			gm.locks = make(map[string]*lockRecord)
+		}
+		record = &lockRecord{stats: LockStats{Name: name}}
+		gm.locks[name] = record
+	}
+	gm.mu.Unlock()
+
+	cached.Store(record)
+	return record
+}
+
+// trackLock records an acquisition of the lock name that was held for held on
+// its record, and attributes the wait and hold to the goroutine that took the
+// lock unless it is zero. The hold is recorded as the handling time of the
+// case. Only the attribution takes gm.mu.
+func (gm *GoroutineManager) trackLock(record *lockRecord, name, suffix string, hold lockHold, held time.Duration) {
+	entered := gm.overheadStart()
+
+	record.mu.Lock()
+	lock := &record.stats
+	if suffix == RLockCaseSuffix {
+		lock.RLocks++
+	} else {
+		lock.Locks++
+	}
+	lock.WaitTime += hold.wait
+	lock.HoldTime += held
+	lock.MaxWait = max(lock.MaxWait, hold.wait)
+	lock.MaxHold = max(lock.MaxHold, held)
+	record.mu.Unlock()
+
+	if hold.id != 0 {
+		gm.attribute(hold.id, name+suffix, nil, hold.wait, held, entered)
+	}
+}
+
+// clone returns a copy of the stats kept in the record
+func (r *lockRecord) clone() *LockStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := r.stats
+	return &c
+}
+
+// GetLockStats returns a copy of the statistics of the locks named name
+func (gm *GoroutineManager) GetLockStats(name string) *LockStats {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	record, exists := gm.locks[name]
+	if !exists {
+		return nil
+	}
+	return record.clone()
+}
+
+// GetAllLockStats returns a copy of the statistics of every lock, keyed by name
+func (gm *GoroutineManager) GetAllLockStats() map[string]*LockStats {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	locks := make(map[string]*LockStats, len(gm.locks))
+	for name, record := range gm.locks {
+		locks[name] = record.clone()
+	}
+	return locks
+}
@@ -0,0 +1,33 @@
+package tracker
+
+import "time"
+
+// GetAcquisitions returns how many times the lock was taken in either mode
+func (ls *LockStats) GetAcquisitions() int {
+	return ls.Locks + ls.RLocks
+}
+
+// GetAverageWait returns the average time an acquisition waited for the lock
+func (ls *LockStats) GetAverageWait() time.Duration {
+	if ls.GetAcquisitions() == 0 {
+		return 0
+	}
+	return ls.WaitTime / time.Duration(ls.GetAcquisitions())
+}
+
+// GetAverageHold returns the average time the lock was held
+func (ls *LockStats) GetAverageHold() time.Duration {
+	if ls.GetAcquisitions() == 0 {
+		return 0
+	}
+	return ls.HoldTime / time.Duration(ls.GetAcquisitions())
+}
+
+// sub removes the acquisitions already present in an earlier copy of the same
+// lock stats, the maximums are kept
+func (ls *LockStats) sub(prev *LockStats) {
+	ls.Locks -= prev.Locks
+	ls.RLocks -= prev.RLocks
+	ls.WaitTime -= prev.WaitTime
+	ls.HoldTime -= prev.HoldTime
+}
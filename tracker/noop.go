@@ -0,0 +1,382 @@
+//go:build idlespy_off
+
+package tracker
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// This file replaces the tracking implementation when building with the
+// idlespy_off tag. Every method keeps its signature but does nothing, so calls
+// compile away on hot paths. Keep it in sync with the exported API of the
+// files tagged !idlespy_off.
+
+// snapshotStream, signalHandler, sampler, exemplarHeap and occupancySampler
+// have no state when tracking is compiled out
+type snapshotStream struct{}
+type signalHandler struct{}
+type sampler struct{}
+type exemplarHeap struct{}
+type occupancySampler struct{}
+
+// NewGoroutineManager creates a manager that never records anything
+func NewGoroutineManager() *GoroutineManager {
+	return &GoroutineManager{
+		Stats:   make(map[GoroutineId]*GoroutineStats),
+		mu:      &sync.RWMutex{},
+		flushMu: &sync.Mutex{},
+		Wg:      &sync.WaitGroup{},
+	}
+}
+
+// TrackGoroutineStart only takes a WaitGroup slot for the goroutine
+func (gm *GoroutineManager) TrackGoroutineStart() GoroutineId {
+	gm.Wg.Add(1)
+	return 0
+}
+
+// TrackGoroutineStartInGroup only takes a WaitGroup slot for the goroutine
+func (gm *GoroutineManager) TrackGoroutineStartInGroup(group string) GoroutineId {
+	gm.Wg.Add(1)
+	return 0
+}
+
+// TrackGoroutineEnd only releases the goroutine's WaitGroup slot
+func (gm *GoroutineManager) TrackGoroutineEnd(id GoroutineId) {
+	gm.Wg.Done()
+}
+
+// TrackGoroutineEndReason only releases the goroutine's WaitGroup slot
+func (gm *GoroutineManager) TrackGoroutineEndReason(id GoroutineId, reason ExitReason) {
+	gm.Wg.Done()
+}
+
+// TrackSelectCase does nothing
+func (gm *GoroutineManager) TrackSelectCase(caseName string, duration time.Duration, id GoroutineId) {
+}
+
+// TrackSelectCaseHandled does nothing
+func (gm *GoroutineManager) TrackSelectCaseHandled(caseName string, wait, handling time.Duration, id GoroutineId) {
+}
+
+// TrackNestedSelectCase does nothing
+func (gm *GoroutineManager) TrackNestedSelectCase(parent, caseName string, duration time.Duration, id GoroutineId) {
+}
+
+// TrackSiteCase does nothing
+func (gm *GoroutineManager) TrackSiteCase(site, caseName string, duration time.Duration, id GoroutineId) {
+}
+
+// BeginSpan returns a span whose End does nothing
+func (gm *GoroutineManager) BeginSpan(name string, id GoroutineId) Span {
+	return Span{}
+}
+
+// End does nothing
+func (s Span) End() {
+}
+
+// NewChan creates a plain channel with the given buffer size
+func NewChan[T any](gm *GoroutineManager, name string, size int) *Chan[T] {
+	return &Chan[T]{gm: gm, name: name, ch: make(chan T, size)}
+}
+
+// Send sends v on the channel
+func (c *Chan[T]) Send(v T) {
+	c.ch <- v
+}
+
+// SendFrom sends v on the channel
+func (c *Chan[T]) SendFrom(v T, id GoroutineId) {
+	c.ch <- v
+}
+
+// Recv receives a value from the channel
+func (c *Chan[T]) Recv() (v T, ok bool) {
+	v, ok = <-c.ch
+	return v, ok
+}
+
+// RecvFrom receives a value from the channel
+func (c *Chan[T]) RecvFrom(id GoroutineId) (v T, ok bool) {
+	v, ok = <-c.ch
+	return v, ok
+}
+
+// Close closes the channel
+func (c *Chan[T]) Close() {
+	close(c.ch)
+}
+
+// C returns the underlying channel
+func (c *Chan[T]) C() chan T {
+	return c.ch
+}
+
+// Len returns the number of values buffered in the channel
+func (c *Chan[T]) Len() int {
+	return len(c.ch)
+}
+
+// Cap returns the buffer size of the channel
+func (c *Chan[T]) Cap() int {
+	return cap(c.ch)
+}
+
+// GetChannelStats always returns nil
+func (gm *GoroutineManager) GetChannelStats(name string) *ChannelStats {
+	return nil
+}
+
+// GetAllChannelStats always returns an empty map
+func (gm *GoroutineManager) GetAllChannelStats() map[string]*ChannelStats {
+	return map[string]*ChannelStats{}
+}
+
+// Lock locks the mutex
+func (m *Mutex) Lock() {
+	m.mu.Lock()
+}
+
+// LockFrom locks the mutex
+func (m *Mutex) LockFrom(id GoroutineId) {
+	m.mu.Lock()
+}
+
+// TryLock tries to lock the mutex without waiting
+func (m *Mutex) TryLock() bool {
+	return m.mu.TryLock()
+}
+
+// Unlock unlocks the mutex
+func (m *Mutex) Unlock() {
+	m.mu.Unlock()
+}
+
+// Lock locks the mutex for writing
+func (rw *RWMutex) Lock() {
+	rw.mu.Lock()
+}
+
+// LockFrom locks the mutex for writing
+func (rw *RWMutex) LockFrom(id GoroutineId) {
+	rw.mu.Lock()
+}
+
+// TryLock tries to lock the mutex for writing without waiting
+func (rw *RWMutex) TryLock() bool {
+	return rw.mu.TryLock()
+}
+
+// Unlock unlocks the mutex for writing
+func (rw *RWMutex) Unlock() {
+	rw.mu.Unlock()
+}
+
+// RLock locks the mutex for reading
+func (rw *RWMutex) RLock() {
+	rw.mu.RLock()
+}
+
+// RLockFrom locks the mutex for reading
+func (rw *RWMutex) RLockFrom(id GoroutineId) {
+	rw.mu.RLock()
+}
+
+// TryRLock tries to lock the mutex for reading without waiting
+func (rw *RWMutex) TryRLock() bool {
+	return rw.mu.TryRLock()
+}
+
+// RUnlock undoes a single RLock
+func (rw *RWMutex) RUnlock() {
+	rw.mu.RUnlock()
+}
+
+// GetLockStats always returns nil
+func (gm *GoroutineManager) GetLockStats(name string) *LockStats {
+	return nil
+}
+
+// GetAllLockStats always returns an empty map
+func (gm *GoroutineManager) GetAllLockStats() map[string]*LockStats {
+	return map[string]*LockStats{}
+}
+
+// Add adds delta to the WaitGroup counter
+func (wg *WaitGroup) Add(delta int) {
+	wg.wg.Add(delta)
+}
+
+// Done decrements the WaitGroup counter by one
+func (wg *WaitGroup) Done() {
+	wg.wg.Done()
+}
+
+// Wait blocks until the WaitGroup counter is zero
+func (wg *WaitGroup) Wait() {
+	wg.wg.Wait()
+}
+
+// NewCond creates a plain condition variable with the locker l
+func NewCond(gm *GoroutineManager, name string, l sync.Locker) *Cond {
+	return &Cond{L: l, gm: gm, name: name, cond: sync.NewCond(l)}
+}
+
+// Wait unlocks c.L, waits to be woken and locks c.L again
+func (c *Cond) Wait() {
+	c.cond.Wait()
+}
+
+// Signal wakes one goroutine waiting on c, if there is any
+func (c *Cond) Signal() {
+	c.cond.Signal()
+}
+
+// Broadcast wakes all goroutines waiting on c
+func (c *Cond) Broadcast() {
+	c.cond.Broadcast()
+}
+
+// NewSemaphore creates a plain weighted semaphore of size units
+func NewSemaphore(gm *GoroutineManager, name string, size int64) *Semaphore {
+	return &Semaphore{gm: gm, name: name, size: size}
+}
+
+// Acquire takes n units, blocking until they are available or ctx is done
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	return s.acquire(ctx, n)
+}
+
+// TryAcquire takes n units without blocking and reports whether it succeeded
+func (s *Semaphore) TryAcquire(n int64) bool {
+	return s.tryAcquire(n)
+}
+
+// Release gives back n units
+func (s *Semaphore) Release(n int64) {
+	s.release(n)
+}
+
+// StartOccupancySampling does nothing
+func (gm *GoroutineManager) StartOccupancySampling(interval time.Duration) error {
+	return nil
+}
+
+// WatchChannel does nothing
+func (gm *GoroutineManager) WatchChannel(name string, length func() int, capacity int) {
+}
+
+// StopOccupancySampling does nothing
+func (gm *GoroutineManager) StopOccupancySampling() {
+}
+
+// WatchContext does nothing
+func (gm *GoroutineManager) WatchContext(ctx context.Context, id GoroutineId) {
+}
+
+// SetCaseKind does nothing
+func (gm *GoroutineManager) SetCaseKind(caseName string, kind CaseKind) {
+}
+
+// SetCaseSampling does nothing
+func (gm *GoroutineManager) SetCaseSampling(caseName string, sampling Sampling) {
+}
+
+// RegisterCase always returns the zero handle
+func (gm *GoroutineManager) RegisterCase(caseName string) CaseHandle {
+	return 0
+}
+
+// TrackCase does nothing
+func (gm *GoroutineManager) TrackCase(handle CaseHandle, duration time.Duration, id GoroutineId) {
+}
+
+// TrackCaseHandled does nothing
+func (gm *GoroutineManager) TrackCaseHandled(handle CaseHandle, wait, handling time.Duration, id GoroutineId) {
+}
+
+// GetGoroutineStats always returns nil
+func (gm *GoroutineManager) GetGoroutineStats(id GoroutineId) *GoroutineStats {
+	return nil
+}
+
+// GetAllStats always returns an empty map
+func (gm *GoroutineManager) GetAllStats() map[GoroutineId]*GoroutineStats {
+	return map[GoroutineId]*GoroutineStats{}
+}
+
+// GetAllGroupStats always returns an empty map
+func (gm *GoroutineManager) GetAllGroupStats() map[string]*GroupStats {
+	return map[string]*GroupStats{}
+}
+
+// Snapshot always returns an empty snapshot
+func (gm *GoroutineManager) Snapshot() *Snapshot {
+	return &Snapshot{Stats: map[GoroutineId]*GoroutineStats{}}
+}
+
+// SnapshotAndReset always returns an empty snapshot
+func (gm *GoroutineManager) SnapshotAndReset() *Snapshot {
+	return &Snapshot{Stats: map[GoroutineId]*GoroutineStats{}}
+}
+
+// Done only waits for the goroutines to finish
+func (gm *GoroutineManager) Done() error {
+	gm.Wg.Wait()
+	return nil
+}
+
+// Flush does nothing
+func (gm *GoroutineManager) Flush() error {
+	return nil
+}
+
+// StartStream does nothing
+func (gm *GoroutineManager) StartStream(path string, interval time.Duration) error {
+	return nil
+}
+
+// StopStream does nothing
+func (gm *GoroutineManager) StopStream() error {
+	return nil
+}
+
+// HandleSignals does nothing
+func (gm *GoroutineManager) HandleSignals() {
+}
+
+// RecoverAndFlush only releases the goroutine's WaitGroup slot, panics keep
+// unwinding since nothing recovers them
+func (gm *GoroutineManager) RecoverAndFlush(id GoroutineId) {
+	gm.Wg.Done()
+}
+
+// Exit terminates the process with the given status code
+func (gm *GoroutineManager) Exit(code int) {
+	os.Exit(code)
+}
+
+// ServeHTTP responds that tracking is compiled out
+func (gm *GoroutineManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "idlespy is compiled out", http.StatusNotFound)
+}
+
+// ListenAndServe does nothing
+func (gm *GoroutineManager) ListenAndServe(addr string) error {
+	return nil
+}
+
+// Now returns the zero time
+func Now() time.Time {
+	return time.Time{}
+}
+
+// Since returns zero
+func Since(t time.Time) time.Duration {
+	return 0
+}
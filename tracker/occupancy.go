@@ -0,0 +1,172 @@
+//go:build !idlespy_off
+
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// maxOccupancyPoints bounds the occupancy series kept per channel
+const maxOccupancyPoints = 256
+
+// occupancySampler periodically samples the buffer of every channel
+type occupancySampler struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// StartOccupancySampling records the fill ratio of every buffered channel
+// created with NewChan or registered with WatchChannel every interval, until
+// StopOccupancySampling or Done is called
+func (gm *GoroutineManager) StartOccupancySampling(interval time.Duration) error {
+	if !gm.Enabled {
+		return nil
+	}
+
+	if interval <= 0 {
+		return fmt.Errorf("invalid occupancy sampling interval: %v", interval)
+	}
+
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if gm.occupancy != nil {
+		return errors.New("occupancy sampling already started")
+	}
+
+	gm.occupancy = &occupancySampler{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go gm.runOccupancySampler(gm.occupancy, interval)
+
+	return nil
+}
+
+// WatchChannel registers a plain channel under name so its occupancy is
+// sampled like the buffer of a Chan, e.g.
+//
+//	gm.WatchChannel("results", func() int { return len(results) }, cap(results))
+//
+// Its sends and receives are not timed.
+func (gm *GoroutineManager) WatchChannel(name string, length func() int, capacity int) {
+	if !gm.Enabled {
+		return
+	}
+
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	record := gm.channelRecordLocked(name)
+	record.mu.Lock()
+	record.stats.Capacity = capacity
+	record.stats.length = length
+	record.mu.Unlock()
+}
+
+// StopOccupancySampling stops sampling channel occupancy, the samples taken
+// so far are kept
+func (gm *GoroutineManager) StopOccupancySampling() {
+	gm.mu.Lock()
+	sampler := gm.occupancy
+	gm.occupancy = nil
+	gm.mu.Unlock()
+
+	if sampler == nil {
+		return
+	}
+
+	close(sampler.stop)
+	<-sampler.done
+}
+
+func (gm *GoroutineManager) runOccupancySampler(sampler *occupancySampler, interval time.Duration) {
+	defer close(sampler.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var records []*channelRecord
+	for {
+		select {
+		case now := <-ticker.C:
+			records = gm.sampleOccupancy(now, interval, records[:0])
+		case <-sampler.stop:
+			return
+		}
+	}
+}
+
+// sampleOccupancy records the current fill ratio of every buffered channel.
+// The channel records are copied into records under a read lock and sampled
+// outside of gm.mu, so tracking is not held up while lengths are read. It
+// returns records for reuse on the next tick.
+func (gm *GoroutineManager) sampleOccupancy(now time.Time, interval time.Duration, records []*channelRecord) []*channelRecord {
+	gm.mu.RLock()
+	for _, record := range gm.channels {
+		records = append(records, record)
+	}
+	gm.mu.RUnlock()
+
+	for _, record := range records {
+		record.mu.Lock()
+		channel := &record.stats
+		if channel.length != nil && channel.Capacity > 0 {
+			if channel.Occupancy == nil {
+				channel.Occupancy = &OccupancyStats{stride: 1}
+			}
+			channel.Occupancy.add(now, float64(channel.length())/float64(channel.Capacity), interval)
+		}
+		record.mu.Unlock()
+	}
+	return records
+}
+
+// add records a fill ratio sampled at now, standing for the time since the
+// previous sample or interval for the first one
+func (oc *OccupancyStats) add(now time.Time, fill float64, interval time.Duration) {
+	elapsed := interval
+	if !oc.last.IsZero() {
+		elapsed = now.Sub(oc.last)
+	}
+	oc.last = now
+
+	if oc.Samples == 0 || fill < oc.MinFill {
+		oc.MinFill = fill
+	}
+	if oc.Samples == 0 || fill > oc.MaxFill {
+		oc.MaxFill = fill
+	}
+	oc.Samples++
+	oc.TotalFill += fill
+	switch fill {
+	case 1:
+		oc.TimeAtFull += elapsed
+	case 0:
+		oc.TimeAtEmpty += elapsed
+	}
+
+	if oc.pending == 0 {
+		oc.pendingStart = now
+	}
+	oc.pending++
+	oc.pendingFill += fill
+	if oc.pending < oc.stride {
+		return
+	}
+
+	oc.Series = append(oc.Series, OccupancyPoint{Time: oc.pendingStart, Fill: oc.pendingFill / float64(oc.pending)})
+	oc.pending = 0
+	oc.pendingFill = 0
+
+	if len(oc.Series) >= maxOccupancyPoints {
+		for i := range len(oc.Series) / 2 {
+			a, b := oc.Series[2*i], oc.Series[2*i+1]
+			oc.Series[i] = OccupancyPoint{Time: a.Time, Fill: (a.Fill + b.Fill) / 2}
+		}
+		oc.Series = oc.Series[:len(oc.Series)/2]
+		oc.stride *= 2
+	}
+}
@@ -0,0 +1,146 @@
+//go:build !idlespy_off
+
+package tracker
+
+import (
+	"container/heap"
+	"maps"
+	"time"
+)
+
+// exemplar is a finished goroutine kept individually by the retention policy
+type exemplar struct {
+	stats    *GoroutineStats
+	blocked  time.Duration
+	lifetime time.Duration
+}
+
+// exemplarHeap orders finished goroutines from least to most blocked, so the
+// root is the next one to roll up
+type exemplarHeap []exemplar
+
+func (h exemplarHeap) Len() int { return len(h) }
+
+func (h exemplarHeap) Less(i, j int) bool {
+	if h[i].blocked != h[j].blocked {
+		return h[i].blocked < h[j].blocked
+	}
+	return h[i].lifetime < h[j].lifetime
+}
+
+func (h exemplarHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *exemplarHeap) Push(x any) { *h = append(*h, x.(exemplar)) }
+
+func (h *exemplarHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = exemplar{}
+	*h = old[:len(old)-1]
+	return e
+}
+
+// TrackGoroutineStartInGroup records the start of a goroutine that belongs to
+// group, along with the goroutine that created it and where. Once finished, the goroutine is rolled up into the group's aggregate
+// when MaxFinishedGoroutines evicts it.
+func (gm *GoroutineManager) TrackGoroutineStartInGroup(group string) GoroutineId {
+	gm.Wg.Add(1)
+	if !gm.Enabled {
+		return 0
+	}
+
+	entered := gm.overheadStart()
+	id, parent, spawnSite := goroutineOrigin()
+
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	stats := gm.goroutineStatsLocked(id)
+	stats.Group = group
+	stats.ParentId = parent
+	stats.SpawnSite = spawnSite
+	if !entered.IsZero() && entered.Before(stats.StartTime) {
+		entered = stats.StartTime
+	}
+	gm.addOverheadLocked(stats, entered)
+
+	return id
+}
+
+// GetAllGroupStats returns the aggregates of the rolled up goroutines, keyed by group
+func (gm *GoroutineManager) GetAllGroupStats() map[string]*GroupStats {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	return maps.Clone(gm.groups)
+}
+
+// retainLocked applies the retention policy to a goroutine that just
+// finished, rolling up the least blocked finished goroutines once more than
+// MaxFinishedGoroutines are kept. Caller must hold gm.mu.
+func (gm *GoroutineManager) retainLocked(stats *GoroutineStats) {
+	if gm.MaxFinishedGoroutines <= 0 {
+		return
+	}
+
+	heap.Push(&gm.exemplars, exemplar{
+		stats:    stats,
+		blocked:  stats.GetTotalSelectBlockedTime(),
+		lifetime: stats.GetGoroutineLifetime(),
+	})
+	for gm.exemplars.Len() > gm.MaxFinishedGoroutines {
+		evicted := heap.Pop(&gm.exemplars).(exemplar)
+		gm.rollUpLocked(evicted.stats)
+	}
+}
+
+// rollUpLocked folds a finished goroutine into its group and removes it from
+// Stats. Caller must hold gm.mu.
+func (gm *GoroutineManager) rollUpLocked(stats *GoroutineStats) {
+	delete(gm.Stats, stats.GoroutineId)
+
+	name := stats.Group
+	if name == "" {
+		name = DefaultGroup
+	}
+	if gm.groups == nil {
+		gm.groups = make(map[string]*GroupStats)
+	}
+	group, exists := gm.groups[name]
+	if !exists {
+		group = &GroupStats{Group: name, SelectStats: make(map[string]*SelectStats)}
+		gm.groups[name] = group
+	}
+
+	group.Goroutines++
+	group.Lifetime += stats.GetGoroutineLifetime()
+	group.Overhead += stats.Overhead
+	group.WorkingTime += stats.GetWorkingTime()
+	group.Spans = mergeSpans(group.Spans, stats.Spans)
+	if group.Exits == nil {
+		group.Exits = &ExitStats{Group: name}
+	}
+	group.Exits.add(stats)
+	if stats.ShutdownLatency > 0 {
+		if group.Shutdown == nil {
+			group.Shutdown = &ShutdownStats{Group: name}
+		}
+		group.Shutdown.addExit(stats.GoroutineId, stats.ShutdownLatency)
+	}
+
+	maxLatencies := gm.MaxLatencySamples
+	if maxLatencies == 0 {
+		maxLatencies = defaultGroupLatencySamples
+	}
+	for caseName, caseStats := range stats.SelectStats {
+		target, exists := group.SelectStats[caseName]
+		if !exists {
+			target = gm.newSelectStats(maxLatencies)
+			target.Parent = caseStats.Parent
+			target.Site = caseStats.Site
+			target.Kind = caseStats.Kind
+			target.CallSite = caseStats.CallSite
+			group.SelectStats[caseName] = target
+		}
+		target.merge(caseStats)
+	}
+}
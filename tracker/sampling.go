@@ -0,0 +1,132 @@
+//go:build !idlespy_off
+
+package tracker
+
+import (
+	"log"
+	"maps"
+	"sync/atomic"
+	"time"
+)
+
+// maxCaseSamplers bounds the samplers created for case names when
+// MaxCaseNames is unlimited
+const maxCaseSamplers = 4096
+
+// sampler decides which hits of the select cases it covers are recorded. It is
+// shared by all goroutines and only uses atomics so skipped hits never take
+// gm.mu. Skipped hits are counted per sampler, not per goroutine, so with
+// Interval a recorded hit also stands for hits other goroutines skipped.
+type sampler struct {
+	every    uint64
+	interval int64
+	// hits seen, used by 1-in-N sampling
+	calls atomic.Uint64
+	// hits skipped since the last recorded one, used by time-based sampling
+	skipped atomic.Uint64
+	// unix nanoseconds of the last recorded hit
+	last atomic.Int64
+}
+
+func newSampler(sampling Sampling) *sampler {
+	return &sampler{
+		every:    uint64(max(sampling.Every, 1)),
+		interval: int64(sampling.Interval),
+	}
+}
+
+// sample reports whether the current hit must be recorded and how many hits
+// the recorded one stands for
+func (s *sampler) sample() (int, bool) {
+	if s.interval > 0 {
+		now := time.Now().UnixNano()
+		last := s.last.Load()
+		if now-last < s.interval || !s.last.CompareAndSwap(last, now) {
+			s.skipped.Add(1)
+			return 0, false
+		}
+		return int(s.skipped.Swap(0)) + 1, true
+	}
+
+	if s.calls.Add(1)%s.every != 0 {
+		return 0, false
+	}
+	return int(s.every), true
+}
+
+// SetCaseSampling overrides the manager's Sampling for a single select case.
+// At most MaxCaseNames cases get their own sampler, further names are ignored.
+func (gm *GoroutineManager) SetCaseSampling(caseName string, sampling Sampling) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if samplers := gm.samplers.Load(); samplers != nil {
+		if _, exists := (*samplers)[caseName]; !exists && gm.caseMapFullLocked(len(*samplers)) {
+			log.Printf("IdleSpy: ignoring sampling of select case %q, MaxCaseNames reached", caseName)
+			return
+		}
+	}
+
+	gm.storeSampler(caseName, newSampler(sampling), true)
+}
+
+// sample applies the sampling configured for caseName, returning the weight
+// of the hit and whether it must be recorded. Every case name is sampled on
+// its own, so whether a hit is kept does not depend on other cases.
+func (gm *GoroutineManager) sample(caseName string) (int, bool) {
+	if samplers := gm.samplers.Load(); samplers != nil {
+		if s, ok := (*samplers)[caseName]; ok {
+			return s.sample()
+		}
+	}
+
+	if !gm.Sampling.enabled() {
+		return 1, true
+	}
+
+	return gm.caseSampler(caseName).sample()
+}
+
+// caseSampler returns the sampler of a case name seen for the first time,
+// creating it with the manager's Sampling. Past MaxCaseNames samplers, or
+// maxCaseSamplers when it is unlimited, further names share the sampler of
+// OtherCaseName.
+func (gm *GoroutineManager) caseSampler(caseName string) *sampler {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	size := 0
+	if samplers := gm.samplers.Load(); samplers != nil {
+		if s, ok := (*samplers)[caseName]; ok {
+			return s
+		}
+		size = len(*samplers)
+	}
+
+	limit := gm.MaxCaseNames
+	if limit <= 0 {
+		limit = maxCaseSamplers
+	}
+	if size >= limit {
+		caseName = OtherCaseName
+	}
+
+	return gm.storeSampler(caseName, newSampler(gm.Sampling), false)
+}
+
+// storeSampler publishes a sampler for caseName, keeping an existing one
+// unless replace is set, and returns the sampler in use. The map is copied on
+// write so readers never lock. Caller must hold gm.mu.
+func (gm *GoroutineManager) storeSampler(caseName string, s *sampler, replace bool) *sampler {
+	samplers := make(map[string]*sampler)
+	if current := gm.samplers.Load(); current != nil {
+		if existing, ok := (*current)[caseName]; ok && !replace {
+			return existing
+		}
+		samplers = maps.Clone(*current)
+	}
+
+	samplers[caseName] = s
+	gm.samplers.Store(&samplers)
+	return s
+}
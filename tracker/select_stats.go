@@ -1,6 +1,10 @@
 package tracker
 
-import "time"
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
 
 // GetCaseHits returns the number of times this case was hit
 func (ss *SelectStats) GetCaseHits() int {
@@ -12,7 +16,66 @@ func (ss *SelectStats) GetCaseTime() time.Duration {
 	return ss.BlockedCaseTime
 }
 
+// GetHandlingTime returns the total time the case's handler ran
+func (ss *SelectStats) GetHandlingTime() time.Duration {
+	return ss.HandlingTime
+}
+
+// GetSampledHits returns the number of hits that were actually measured
+func (ss *SelectStats) GetSampledHits() int {
+	return ss.SampledHits
+}
+
+// GetSampleRate returns the fraction of hits that were measured, 1 when the
+// case is not sampled
+func (ss *SelectStats) GetSampleRate() float64 {
+	if ss.CaseHits == 0 {
+		return 1
+	}
+	return float64(ss.SampledHits) / float64(ss.CaseHits)
+}
+
 // Get Average
 func (ss *SelectStats) GetAverage() time.Duration {
 	return ss.BlockedCaseTime / time.Duration(ss.CaseHits)
 }
+
+// GetWindowStats returns the statistics recorded during the last window,
+// percentiles are approximated to the nearest power-of-two bucket
+func (ss *SelectStats) GetWindowStats(window time.Duration) WindowStats {
+	if ss.window == nil {
+		return WindowStats{Window: window}
+	}
+	return ss.window.stats(window)
+}
+
+// GetWindows returns the statistics for every configured window
+func (ss *SelectStats) GetWindows() []WindowStats {
+	if ss.window == nil {
+		return nil
+	}
+
+	windows := make([]WindowStats, 0, len(ss.window.windows))
+	for _, window := range ss.window.windows {
+		windows = append(windows, ss.window.stats(window))
+	}
+	return windows
+}
+
+// String returns the call site as file:line, e.g. "worker.go:42"
+func (cs *CallSite) String() string {
+	if cs == nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", filepath.Base(cs.File), cs.Line)
+}
+
+// key identifies the location by its full file path and line, so files with
+// the same name in different packages stay apart. String is the short form
+// shown in reports.
+func (cs *CallSite) key() string {
+	if cs == nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", cs.File, cs.Line)
+}
@@ -0,0 +1,114 @@
+package tracker
+
+import "context"
+
+// semaphoreWaiter is an acquisition waiting for n units, ready is closed once
+// they are granted
+type semaphoreWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// acquire takes n units, waiting for them in arrival order until ctx is done
+func (s *Semaphore) acquire(ctx context.Context, n int64) error {
+	done := ctx.Done()
+
+	s.mu.Lock()
+	select {
+	case <-done:
+		s.mu.Unlock()
+		return ctx.Err()
+	default:
+	}
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+
+	if n > s.size {
+		// never satisfiable, don't make the other waiters wait behind it
+		s.mu.Unlock()
+		<-done
+		return ctx.Err()
+	}
+
+	ready := make(chan struct{})
+	elem := s.waiters.PushBack(semaphoreWaiter{n: n, ready: ready})
+	s.mu.Unlock()
+
+	select {
+	case <-done:
+		s.mu.Lock()
+		select {
+		case <-ready:
+			// granted right after ctx was done, give the units back
+			s.cur -= n
+			s.notifyWaitersLocked()
+		default:
+			isFront := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			// the waiters behind it may fit now
+			if isFront && s.size > s.cur {
+				s.notifyWaitersLocked()
+			}
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+
+	case <-ready:
+		// granted, unless ctx was done in the meantime
+		select {
+		case <-done:
+			s.release(n)
+			return ctx.Err()
+		default:
+		}
+		return nil
+	}
+}
+
+// tryAcquire takes n units without waiting and reports whether it succeeded
+func (s *Semaphore) tryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		return true
+	}
+	return false
+}
+
+// release gives back n units, waking the waiters they are enough for
+func (s *Semaphore) release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cur -= n
+	if s.cur < 0 {
+		panic("tracker: semaphore released more than held")
+	}
+	s.notifyWaitersLocked()
+}
+
+// notifyWaitersLocked grants units to the waiters in arrival order until the
+// first one that does not fit. Caller must hold s.mu.
+func (s *Semaphore) notifyWaitersLocked() {
+	for {
+		next := s.waiters.Front()
+		if next == nil {
+			return
+		}
+
+		w := next.Value.(semaphoreWaiter)
+		if s.size-s.cur < w.n {
+			// keep arrival order so large acquisitions are not starved
+			return
+		}
+
+		s.cur += w.n
+		s.waiters.Remove(next)
+		close(w.ready)
+	}
+}
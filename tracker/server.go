@@ -0,0 +1,48 @@
+package tracker
+
+import (
+	"errors"
+	"net"
+	"net/http"
+)
+
+// listen binds addr and keeps the server on the manager so Done can close it
+func (gm *GoroutineManager) listen(addr string) (*http.Server, net.Listener, error) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if gm.server != nil {
+		return nil, nil, errors.New("already serving")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gm.server = &http.Server{Addr: addr, Handler: gm}
+	return gm.server, listener, nil
+}
+
+// serve blocks until server is closed, closing it is not an error
+func serve(server *http.Server, listener net.Listener) error {
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// stopServing closes the server started by ListenAndServe, if any
+func (gm *GoroutineManager) stopServing() error {
+	gm.mu.Lock()
+	server := gm.server
+	gm.server = nil
+	gm.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+
+	return server.Close()
+}
@@ -0,0 +1,80 @@
+//go:build !idlespy_off
+
+package tracker
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// WatchContext records when ctx is cancelled, so the time the goroutine then
+// takes to reach TrackGoroutineEnd is reported as its shutdown latency.
+// Watching another context replaces the previous one.
+func (gm *GoroutineManager) WatchContext(ctx context.Context, id GoroutineId) {
+	if !gm.Enabled {
+		return
+	}
+
+	entered := gm.overheadStart()
+
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	stats, tracked := gm.Stats[id]
+	if !tracked || !stats.EndTime.IsZero() {
+		return
+	}
+	defer gm.addOverheadLocked(stats, entered)
+
+	if watch, exists := gm.watches[id]; exists {
+		watch.stop()
+	}
+	if gm.watches == nil {
+		gm.watches = make(map[GoroutineId]contextWatch)
+	}
+	cancelledAt := new(atomic.Pointer[time.Time])
+	gm.watches[id] = contextWatch{
+		stop: context.AfterFunc(ctx, func() {
+			now := time.Now()
+			if cancelledAt.CompareAndSwap(nil, &now) {
+				gm.recordCancel(stats, now)
+			}
+		}),
+		cancelledAt: cancelledAt,
+	}
+}
+
+// recordCancel records the cancellation of a watched goroutine's context at
+// now, unless the goroutine ended or was dropped in the meantime
+func (gm *GoroutineManager) recordCancel(stats *GoroutineStats, now time.Time) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if gm.Stats[stats.GoroutineId] != stats || !stats.EndTime.IsZero() || !stats.CancelledAt.IsZero() {
+		return
+	}
+	stats.CancelledAt = now
+}
+
+// endWatchLocked stops watching the goroutine's context and records its
+// shutdown latency if the context was cancelled. Caller must hold gm.mu.
+func (gm *GoroutineManager) endWatchLocked(stats *GoroutineStats) {
+	if watch, exists := gm.watches[stats.GoroutineId]; exists {
+		delete(gm.watches, stats.GoroutineId)
+		// the AfterFunc started, it may still be waiting for gm.mu with the
+		// cancellation already stamped. The goroutine can also see the
+		// cancellation and end before the AfterFunc runs, it then exited
+		// right away.
+		if !watch.stop() && stats.CancelledAt.IsZero() {
+			end := stats.EndTime
+			watch.cancelledAt.CompareAndSwap(nil, &end)
+			stats.CancelledAt = *watch.cancelledAt.Load()
+		}
+	}
+
+	if !stats.CancelledAt.IsZero() {
+		// at least a nanosecond, a zero latency means the goroutine is still running
+		stats.ShutdownLatency = max(stats.EndTime.Sub(stats.CancelledAt), time.Nanosecond)
+	}
+}
@@ -0,0 +1,156 @@
+package tracker
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// WasCancelled reports whether the context registered with WatchContext was cancelled
+func (gs *GoroutineStats) WasCancelled() bool {
+	return !gs.CancelledAt.IsZero()
+}
+
+// GetShutdownLatency returns how long the goroutine took to end after its
+// context was cancelled, zero if it was not cancelled or is still running
+func (gs *GoroutineStats) GetShutdownLatency() time.Duration {
+	return gs.ShutdownLatency
+}
+
+// GetAverageLatency returns the average time the goroutines took to exit
+func (ss *ShutdownStats) GetAverageLatency() time.Duration {
+	if ss.Exits == 0 {
+		return 0
+	}
+	return ss.TotalLatency / time.Duration(ss.Exits)
+}
+
+// GetPercentile returns the nth percentile exit latency
+func (ss *ShutdownStats) GetPercentile(n float64) time.Duration {
+	if len(ss.latencies) == 0 {
+		return 0
+	}
+
+	latencies := slices.Clone(ss.latencies)
+	slices.Sort(latencies)
+
+	index := int(float64(len(latencies)-1) * n / 100.0)
+	return latencies[index]
+}
+
+// Shutdowns returns the exit latencies of the cancelled goroutines and groups
+// in the snapshot, keyed by group. Goroutines without a group belong to
+// DefaultGroup.
+func (snap *Snapshot) Shutdowns() map[string]*ShutdownStats {
+	shutdowns := make(map[string]*ShutdownStats)
+	groupOf := func(name string) *ShutdownStats {
+		if name == "" {
+			name = DefaultGroup
+		}
+		ss, exists := shutdowns[name]
+		if !exists {
+			ss = &ShutdownStats{Group: name}
+			shutdowns[name] = ss
+		}
+		return ss
+	}
+
+	taken := snap.Taken
+	if taken.IsZero() {
+		taken = time.Now()
+	}
+	for id, stats := range snap.Stats {
+		if !stats.WasCancelled() {
+			continue
+		}
+		if stats.ShutdownLatency > 0 {
+			groupOf(stats.Group).addExit(id, stats.ShutdownLatency)
+		} else {
+			groupOf(stats.Group).addPending(id, taken.Sub(stats.CancelledAt))
+		}
+	}
+	for name, group := range snap.Groups {
+		if group.Shutdown != nil {
+			groupOf(name).merge(group.Shutdown)
+		}
+	}
+	return shutdowns
+}
+
+// addExit records a cancelled goroutine that ended latency after the cancellation
+func (ss *ShutdownStats) addExit(id GoroutineId, latency time.Duration) {
+	ss.addLatency(latency, ss.Exits)
+	ss.Exits++
+	ss.TotalLatency += latency
+	ss.MaxLatency = max(ss.MaxLatency, latency)
+	ss.addSlowest(SlowExit{GoroutineId: id, Latency: latency})
+}
+
+// addPending records a cancelled goroutine that has been running for running
+// since the cancellation
+func (ss *ShutdownStats) addPending(id GoroutineId, running time.Duration) {
+	ss.Pending++
+	ss.addSlowest(SlowExit{GoroutineId: id, Latency: running, Pending: true})
+}
+
+// addLatency keeps latency for percentiles, replacing a kept one by
+// reservoir sampling once defaultGroupLatencySamples are kept
+func (ss *ShutdownStats) addLatency(latency time.Duration, seen int) {
+	if len(ss.latencies) < defaultGroupLatencySamples {
+		ss.latencies = append(ss.latencies, latency)
+	} else if i := rand.IntN(seen + 1); i < defaultGroupLatencySamples {
+		ss.latencies[i] = latency
+	}
+}
+
+// addSlowest keeps exit among the slowest exits if it is slow enough
+func (ss *ShutdownStats) addSlowest(exit SlowExit) {
+	ss.Slowest = append(ss.Slowest, exit)
+	slices.SortStableFunc(ss.Slowest, func(a, b SlowExit) int {
+		return cmp.Compare(b.Latency, a.Latency)
+	})
+	if len(ss.Slowest) > maxSlowestExits {
+		ss.Slowest = ss.Slowest[:maxSlowestExits]
+	}
+}
+
+// merge adds the exits of other, its latencies are fed through the reservoir
+func (ss *ShutdownStats) merge(other *ShutdownStats) {
+	for i, latency := range other.latencies {
+		ss.addLatency(latency, ss.Exits+i)
+	}
+	ss.Exits += other.Exits
+	ss.Pending += other.Pending
+	ss.TotalLatency += other.TotalLatency
+	ss.MaxLatency = max(ss.MaxLatency, other.MaxLatency)
+	for _, exit := range other.Slowest {
+		ss.addSlowest(exit)
+	}
+}
+
+// clone returns a deep copy of the shutdown stats, nil stays nil
+func (ss *ShutdownStats) clone() *ShutdownStats {
+	if ss == nil {
+		return nil
+	}
+	c := *ss
+	c.Slowest = slices.Clone(ss.Slowest)
+	c.latencies = slices.Clone(ss.latencies)
+	return &c
+}
+
+// sub removes the exits already present in an earlier copy of the same
+// shutdown stats, the maximum and the slowest exits are kept
+func (ss *ShutdownStats) sub(prev *ShutdownStats) {
+	ss.Exits -= prev.Exits
+	ss.Pending -= prev.Pending
+	ss.TotalLatency -= prev.TotalLatency
+
+	// once the reservoir starts replacing latencies the new ones can no
+	// longer be told apart, so the whole reservoir is kept
+	replaced := ss.Exits+prev.Exits > defaultGroupLatencySamples
+	if !replaced && len(prev.latencies) <= len(ss.latencies) {
+		ss.latencies = ss.latencies[len(prev.latencies):]
+	}
+}
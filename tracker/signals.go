@@ -0,0 +1,115 @@
+//go:build !idlespy_off
+
+package tracker
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"slices"
+)
+
+// signalHandler forwards process signals to the manager
+type signalHandler struct {
+	ch   chan os.Signal
+	done chan struct{}
+}
+
+// HandleSignals makes the manager write its reports when the process receives
+// a signal. Dump signals (SIGUSR1) write the reports and keep running, stop
+// signals (SIGINT, SIGTERM) write them and then let the signal end the process.
+// The handlers are removed by Done.
+func (gm *GoroutineManager) HandleSignals() {
+	if !gm.Enabled {
+		return
+	}
+
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if gm.signals != nil {
+		return
+	}
+
+	handler := &signalHandler{
+		ch:   make(chan os.Signal, 1),
+		done: make(chan struct{}),
+	}
+	signal.Notify(handler.ch, slices.Concat(dumpSignals, stopSignals)...)
+	gm.signals = handler
+
+	go gm.runSignalHandler(handler)
+}
+
+func (gm *GoroutineManager) runSignalHandler(handler *signalHandler) {
+	for {
+		select {
+		case sig := <-handler.ch:
+			if slices.Contains(dumpSignals, sig) {
+				if err := gm.Flush(); err != nil {
+					log.Printf("Error flushing stats on %v: %v", sig, err)
+				}
+				continue
+			}
+
+			signal.Stop(handler.ch)
+			gm.flushBeforeExit()
+			reraise(sig)
+			return
+		case <-handler.done:
+			return
+		}
+	}
+}
+
+// stopHandlingSignals removes the handlers installed by HandleSignals
+func (gm *GoroutineManager) stopHandlingSignals() {
+	gm.mu.Lock()
+	handler := gm.signals
+	gm.signals = nil
+	gm.mu.Unlock()
+
+	if handler == nil {
+		return
+	}
+
+	signal.Stop(handler.ch)
+	close(handler.done)
+}
+
+// RecoverAndFlush is meant to be deferred in place of TrackGoroutineEnd. It
+// ends the goroutine's tracking and, if the goroutine is panicking, records
+// the panic's value and stack as its exit reason and writes the reports
+// before resuming the panic.
+func (gm *GoroutineManager) RecoverAndFlush(id GoroutineId) {
+	r := recover()
+	if r == nil {
+		gm.TrackGoroutineEnd(id)
+		return
+	}
+
+	gm.trackEnd(id, ExitPanicked, &Panic{GoroutineId: id, Value: fmt.Sprint(r), Stack: string(debug.Stack())})
+
+	gm.flushBeforeExit()
+	panic(r)
+}
+
+// Exit writes the reports and then terminates the process with the given
+// status code, use it instead of os.Exit which skips deferred calls
+func (gm *GoroutineManager) Exit(code int) {
+	gm.flushBeforeExit()
+	os.Exit(code)
+}
+
+// flushBeforeExit closes the stream and writes the reports, errors are only
+// logged since the process is about to go away
+func (gm *GoroutineManager) flushBeforeExit() {
+	if err := gm.StopStream(); err != nil {
+		log.Printf("Error closing stream: %v", err)
+	}
+	if err := gm.Flush(); err != nil {
+		log.Printf("Error flushing stats: %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix && !idlespy_off
+
+package tracker
+
+import "os"
+
+var (
+	dumpSignals []os.Signal
+	stopSignals = []os.Signal{os.Interrupt}
+)
+
+// reraise ends the process, signals cannot be sent to the own process here
+func reraise(os.Signal) {
+	os.Exit(1)
+}
@@ -0,0 +1,23 @@
+//go:build unix && !idlespy_off
+
+package tracker
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+var (
+	dumpSignals = []os.Signal{syscall.SIGUSR1}
+	stopSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+)
+
+// reraise restores the default behavior of sig and sends it again, so the
+// process ends the same way it would have without the handler
+func reraise(sig os.Signal) {
+	signal.Reset(sig)
+	if err := syscall.Kill(syscall.Getpid(), sig.(syscall.Signal)); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,83 @@
+package tracker
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// GetWinRate returns the fraction of the select's runs the case won
+func (ss *SiteStats) GetWinRate(caseName string) float64 {
+	if ss.Hits == 0 {
+		return 0
+	}
+	return float64(ss.Wins[caseName]) / float64(ss.Hits)
+}
+
+// GetFairness returns the normalized entropy of the win distribution: 1 when
+// every case that won did so equally often, approaching 0 when one case
+// dominates. It is 0 when fewer than two cases won.
+func (ss *SiteStats) GetFairness() float64 {
+	if len(ss.Wins) < 2 || ss.Hits == 0 {
+		return 0
+	}
+
+	var entropy float64
+	for _, wins := range ss.Wins {
+		if wins == 0 {
+			continue
+		}
+		p := float64(wins) / float64(ss.Hits)
+		entropy -= p * math.Log(p)
+	}
+	return entropy / math.Log(float64(len(ss.Wins)))
+}
+
+// GetSiteStats returns the goroutine's select sites, keyed by site
+func (gs *GoroutineStats) GetSiteStats() map[string]*SiteStats {
+	sites := make(map[string]*SiteStats)
+	collectSites(sites, gs.SelectStats)
+	return sites
+}
+
+// Sites returns the select sites of every goroutine and group in the
+// snapshot, keyed by site
+func (snap *Snapshot) Sites() map[string]*SiteStats {
+	sites := make(map[string]*SiteStats)
+	for _, stats := range snap.Stats {
+		collectSites(sites, stats.SelectStats)
+	}
+	for _, group := range snap.Groups {
+		collectSites(sites, group.SelectStats)
+	}
+	return sites
+}
+
+// collectSites adds the cases recorded with a site to sites
+func collectSites(sites map[string]*SiteStats, selectStats map[string]*SelectStats) {
+	for caseName, caseStats := range selectStats {
+		if caseStats.Site == "" {
+			continue
+		}
+
+		site, exists := sites[caseStats.Site]
+		if !exists {
+			site = &SiteStats{
+				Site:        caseStats.Site,
+				Name:        caseStats.Site,
+				Wins:        make(map[string]int),
+				CaseBlocked: make(map[string]time.Duration),
+			}
+			if caseStats.CallSite != nil && caseStats.Site == caseStats.CallSite.key() {
+				site.Name = caseStats.CallSite.String()
+			}
+			sites[caseStats.Site] = site
+		}
+
+		name := strings.TrimPrefix(caseName, caseStats.Site+SiteCaseSeparator)
+		site.Hits += caseStats.CaseHits
+		site.BlockedTime += caseStats.BlockedCaseTime
+		site.Wins[name] += caseStats.CaseHits
+		site.CaseBlocked[name] += caseStats.BlockedCaseTime
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !idlespy_off
+
+package tracker
+
+import "time"
+
+// TrackSiteCase records a case of the select statement site. Sites keep
+// cases of different selects apart even when they share a name, and are
+// reported with how often each case won. An empty site is named after the
+// file path and line of the call, so it only groups the cases of a select
+// that are all recorded by the same call.
+func (gm *GoroutineManager) TrackSiteCase(site, caseName string, duration time.Duration, id GoroutineId) {
+	if !gm.Enabled {
+		return
+	}
+
+	var at *CallSite
+	if site == "" || caseName == "" || gm.CaptureCallSites {
+		at = captureCallSite(1)
+	}
+	if site == "" {
+		site = at.key()
+	}
+	gm.trackSelect("", site, caseName, at, duration, 0, id)
+}
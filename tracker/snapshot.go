@@ -0,0 +1,236 @@
+package tracker
+
+import (
+	"slices"
+	"time"
+)
+
+// Delta returns the statistics recorded between two snapshots of the same
+// manager. Both snapshots must belong to the same interval, i.e. the manager
+// must not have been reset between them. Goroutines rolled up between the
+// snapshots are counted entirely in their group's delta.
+func Delta(prev, curr *Snapshot) *Snapshot {
+	delta := &Snapshot{
+		Since:            prev.Taken,
+		Taken:            curr.Taken,
+		Stats:            make(map[GoroutineId]*GoroutineStats),
+		DroppedCaseNames: curr.DroppedCaseNames - prev.DroppedCaseNames,
+		spinRate:         curr.spinRate,
+		spinRatio:        curr.spinRatio,
+	}
+
+	for id, stats := range curr.Stats {
+		if stats.EndTime.Before(prev.Taken) {
+			continue
+		}
+
+		d := stats.clone(prev.Taken, curr.Taken)
+		old, existed := prev.Stats[id]
+		if !existed {
+			delta.Stats[id] = d
+			continue
+		}
+
+		d.Overhead -= old.Overhead
+		d.SpanTime -= old.SpanTime
+		d.blockedInSpans -= old.blockedInSpans
+		d.handlingOutsideSpans -= old.handlingOutsideSpans
+		d.Spans = subSpans(d.Spans, old.Spans)
+		for caseName, caseStats := range d.SelectStats {
+			oldCase, ok := old.SelectStats[caseName]
+			if !ok {
+				continue
+			}
+			caseStats.sub(oldCase)
+			if caseStats.CaseHits == 0 {
+				delete(d.SelectStats, caseName)
+			}
+		}
+		delta.Stats[id] = d
+	}
+
+	for name, group := range curr.Groups {
+		d := group.clone(curr.Taken)
+		if old, existed := prev.Groups[name]; existed {
+			d.sub(old)
+		}
+		if d.Goroutines == 0 {
+			continue
+		}
+		if delta.Groups == nil {
+			delta.Groups = make(map[string]*GroupStats)
+		}
+		delta.Groups[name] = d
+	}
+
+	for name, channel := range curr.Channels {
+		d := channel.clone()
+		if old, existed := prev.Channels[name]; existed {
+			d.sub(old)
+		}
+		if delta.Channels == nil {
+			delta.Channels = make(map[string]*ChannelStats)
+		}
+		delta.Channels[name] = d
+	}
+
+	for name, lock := range curr.Locks {
+		d := *lock
+		if old, existed := prev.Locks[name]; existed {
+			d.sub(old)
+		}
+		if d.GetAcquisitions() == 0 {
+			continue
+		}
+		if delta.Locks == nil {
+			delta.Locks = make(map[string]*LockStats)
+		}
+		delta.Locks[name] = &d
+	}
+
+	return delta
+}
+
+// clone returns a deep copy of the goroutine stats with its lifetime clamped
+// to [since, until]
+func (gs *GoroutineStats) clone(since, until time.Time) *GoroutineStats {
+	c := &GoroutineStats{
+		GoroutineId: gs.GoroutineId,
+		Group:       gs.Group,
+		ParentId:    gs.ParentId,
+		SpawnSite:   gs.SpawnSite,
+		SelectStats: make(map[string]*SelectStats, len(gs.SelectStats)),
+		StartTime:   gs.StartTime,
+		EndTime:     gs.EndTime,
+		Overhead:    gs.Overhead,
+		Spans:       mergeSpans(nil, gs.Spans),
+		SpanTime:    gs.SpanTime,
+
+		CancelledAt:     gs.CancelledAt,
+		ShutdownLatency: gs.ShutdownLatency,
+		ExitReason:      gs.ExitReason,
+		Panic:           gs.Panic,
+
+		blockedInSpans:       gs.blockedInSpans,
+		handlingOutsideSpans: gs.handlingOutsideSpans,
+	}
+	if c.StartTime.Before(since) {
+		c.StartTime = since
+	}
+	if c.EndTime.IsZero() || c.EndTime.After(until) {
+		c.EndTime = until
+	}
+
+	for caseName, caseStats := range gs.SelectStats {
+		c.SelectStats[caseName] = caseStats.clone(until)
+	}
+
+	// spans still open count up to the end of the copy
+	c.openSpans = slices.Clone(gs.openSpans)
+	for len(c.openSpans) > 0 {
+		c.endSpanLocked(c.EndTime, false)
+	}
+	return c
+}
+
+// clone returns a deep copy of the group stats, with its windows frozen at asOf
+func (g *GroupStats) clone(asOf time.Time) *GroupStats {
+	c := &GroupStats{
+		Group:       g.Group,
+		Goroutines:  g.Goroutines,
+		Lifetime:    g.Lifetime,
+		Overhead:    g.Overhead,
+		SelectStats: make(map[string]*SelectStats, len(g.SelectStats)),
+		WorkingTime: g.WorkingTime,
+		Spans:       mergeSpans(nil, g.Spans),
+		Shutdown:    g.Shutdown.clone(),
+		Exits:       g.Exits.clone(),
+	}
+	for caseName, caseStats := range g.SelectStats {
+		c.SelectStats[caseName] = caseStats.clone(asOf)
+	}
+	return c
+}
+
+// sub removes the goroutines already present in an earlier copy of the same group
+func (g *GroupStats) sub(prev *GroupStats) {
+	g.Goroutines -= prev.Goroutines
+	g.Lifetime -= prev.Lifetime
+	g.Overhead -= prev.Overhead
+	g.WorkingTime -= prev.WorkingTime
+	g.Spans = subSpans(g.Spans, prev.Spans)
+	if g.Exits != nil && prev.Exits != nil {
+		g.Exits.sub(prev.Exits)
+	}
+	if g.Shutdown != nil && prev.Shutdown != nil {
+		g.Shutdown.sub(prev.Shutdown)
+		if g.Shutdown.Exits == 0 {
+			g.Shutdown = nil
+		}
+	}
+	for caseName, caseStats := range g.SelectStats {
+		if oldCase, ok := prev.SelectStats[caseName]; ok {
+			caseStats.sub(oldCase)
+			if caseStats.CaseHits == 0 {
+				delete(g.SelectStats, caseName)
+			}
+		}
+	}
+}
+
+// clone returns a deep copy of the select stats, with its windows frozen at asOf
+func (s *SelectStats) clone(asOf time.Time) *SelectStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := &SelectStats{
+		Parent:          s.Parent,
+		Site:            s.Site,
+		CallSite:        s.CallSite,
+		Kind:            s.Kind,
+		BlockedCaseTime: s.BlockedCaseTime,
+		HandlingTime:    s.HandlingTime,
+		CaseHits:        s.CaseHits,
+		SampledHits:     s.SampledHits,
+		latencies:       append([]time.Duration(nil), s.latencies...),
+		maxLatencies:    s.maxLatencies,
+	}
+	if s.window != nil {
+		c.window = s.window.clone(asOf)
+	}
+	return c
+}
+
+// sub removes the measurements already present in an earlier copy of the
+// same select stats
+func (s *SelectStats) sub(prev *SelectStats) {
+	s.BlockedCaseTime -= prev.BlockedCaseTime
+	s.HandlingTime -= prev.HandlingTime
+	s.CaseHits -= prev.CaseHits
+	s.SampledHits -= prev.SampledHits
+
+	// once a bounded reservoir starts replacing latencies the new ones can no
+	// longer be told apart, so the whole reservoir is kept
+	replaced := s.maxLatencies > 0 && s.SampledHits+prev.SampledHits > s.maxLatencies
+	if !replaced && len(prev.latencies) <= len(s.latencies) {
+		s.latencies = s.latencies[len(prev.latencies):]
+	}
+}
+
+// subSpans removes the span statistics already present in an earlier copy,
+// dropping spans that did not end in between
+func subSpans(spans, prev map[string]*SpanStats) map[string]*SpanStats {
+	for name, spanStats := range spans {
+		old, ok := prev[name]
+		if !ok {
+			continue
+		}
+		spanStats.Count -= old.Count
+		spanStats.TotalTime -= old.TotalTime
+		spanStats.SelfTime -= old.SelfTime
+		if spanStats.Count == 0 {
+			delete(spans, name)
+		}
+	}
+	return spans
+}
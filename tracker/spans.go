@@ -0,0 +1,76 @@
+//go:build !idlespy_off
+
+package tracker
+
+import "time"
+
+// BeginSpan starts a named work span on the goroutine, such as computing or
+// doing I/O. Spans nest: a span begun while another is open is its child.
+// Select wait recorded while a span is open is not counted as work.
+func (gm *GoroutineManager) BeginSpan(name string, id GoroutineId) Span {
+	if !gm.Enabled {
+		return Span{}
+	}
+
+	entered := gm.overheadStart()
+
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	stats := gm.goroutineStatsLocked(id)
+	stats.spanSeq++
+	stats.openSpans = append(stats.openSpans, openSpan{
+		name:  name,
+		seq:   stats.spanSeq,
+		start: time.Now(),
+	})
+	gm.addOverheadLocked(stats, entered)
+
+	return Span{gm: gm, id: id, seq: stats.spanSeq}
+}
+
+// End ends the span, along with any nested span that was not ended. Ending a
+// span twice does nothing.
+func (s Span) End() {
+	if s.gm == nil {
+		return
+	}
+	gm := s.gm
+
+	entered := gm.overheadStart()
+
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	stats, exists := gm.Stats[s.id]
+	if !exists {
+		return
+	}
+
+	depth := -1
+	for i, open := range stats.openSpans {
+		if open.seq == s.seq {
+			depth = i
+			break
+		}
+	}
+	if depth < 0 {
+		return
+	}
+
+	now := time.Now()
+	for len(stats.openSpans) > depth {
+		stats.endSpanLocked(now, true)
+	}
+	gm.addOverheadLocked(stats, entered)
+}
+
+// accountHitLocked splits a select hit between the goroutine's spans and the
+// work done outside of them. Caller must hold gm.mu.
+func (gs *GoroutineStats) accountHitLocked(wait, handling time.Duration, weight int) {
+	if len(gs.openSpans) > 0 {
+		gs.blockedInSpans += wait * time.Duration(weight)
+	} else {
+		gs.handlingOutsideSpans += handling * time.Duration(weight)
+	}
+}
@@ -0,0 +1,94 @@
+//go:build !idlespy_off
+
+package tracker
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// originStackSize bounds the stack read to find the creation record of a
+// goroutine. TrackGoroutineStart runs at the top of the goroutine, where the
+// stack is shallow.
+const originStackSize = 4 << 10
+
+// originBufs recycles the stack buffers read by goroutineOrigin
+var originBufs = sync.Pool{
+	New: func() any {
+		buf := make([]byte, originStackSize)
+		return &buf
+	},
+}
+
+// spawnSites interns spawn sites by their location, so goroutines started
+// from the same go statement share one CallSite
+var (
+	spawnSitesMu sync.Mutex
+	spawnSites   = make(map[string]*CallSite)
+)
+
+// goroutineOrigin returns the id of the current goroutine, the id of the
+// goroutine that created it and the go statement it was created at. The
+// parent and site are zero for the main goroutine. When the stack does not
+// fit in originStackSize the creation record at its end is cut off, and the
+// parent is UnknownParent.
+func goroutineOrigin() (id, parent GoroutineId, site *CallSite) {
+	bufp := originBufs.Get().(*[]byte)
+	defer originBufs.Put(bufp)
+
+	n := runtime.Stack(*bufp, false)
+	buf := (*bufp)[:n]
+
+	header, _, _ := bytes.Cut(bytes.TrimPrefix(buf, []byte("goroutine ")), []byte(" "))
+	number, err := strconv.ParseInt(string(header), 10, 64)
+	if err != nil {
+		panic("cannot get goroutine id: " + err.Error())
+	}
+	id = GoroutineId(number)
+	if n == len(*bufp) {
+		return id, UnknownParent, nil
+	}
+
+	// created by pkg.fn in goroutine 1
+	//	/path/file.go:12 +0x25
+	i := bytes.LastIndex(buf, []byte("\ncreated by "))
+	if i < 0 {
+		return id, 0, nil
+	}
+	record, location, _ := bytes.Cut(buf[i+len("\ncreated by "):], []byte("\n"))
+	function, parentField, found := bytes.Cut(record, []byte(" in goroutine "))
+	if found {
+		if number, err := strconv.ParseInt(string(parentField), 10, 64); err == nil {
+			parent = GoroutineId(number)
+		}
+	}
+
+	location, _, _ = bytes.Cut(bytes.TrimSpace(location), []byte(" +0x"))
+	location, _, _ = bytes.Cut(location, []byte("\n"))
+	return id, parent, internSpawnSite(string(function), string(location))
+}
+
+// internSpawnSite returns the shared call site for a function and a
+// file:line location
+func internSpawnSite(function, location string) *CallSite {
+	key := function + " " + location
+
+	spawnSitesMu.Lock()
+	defer spawnSitesMu.Unlock()
+
+	if site, ok := spawnSites[key]; ok {
+		return site
+	}
+
+	site := &CallSite{Function: function, File: location}
+	if i := strings.LastIndexByte(location, ':'); i >= 0 {
+		if line, err := strconv.Atoi(location[i+1:]); err == nil {
+			site.File, site.Line = location[:i], line
+		}
+	}
+	spawnSites[key] = site
+	return site
+}
@@ -0,0 +1,57 @@
+package tracker
+
+import "time"
+
+// GetSpinStats returns how often the goroutine took the default branch of
+// its selects
+func (gs *GoroutineStats) GetSpinStats() SpinStats {
+	return spinStats(gs.SelectStats, gs.GetGoroutineLifetime())
+}
+
+// GetSpinStats returns how often the rolled up goroutines took the default
+// branch of their selects, per second of their summed lifetimes
+func (g *GroupStats) GetSpinStats() SpinStats {
+	return spinStats(g.SelectStats, g.Lifetime)
+}
+
+// IsSpinning reports whether both the rate and the ratio of default hits
+// exceed the thresholds, zero thresholds use DefaultSpinRate and DefaultSpinRatio
+func (ss SpinStats) IsSpinning(rate, ratio float64) bool {
+	if rate <= 0 {
+		rate = DefaultSpinRate
+	}
+	if ratio <= 0 {
+		ratio = DefaultSpinRatio
+	}
+	return ss.DefaultHits > 0 && ss.Rate > rate && ss.Ratio > ratio
+}
+
+// IsSpinning reports whether the spin stats exceed the thresholds of the
+// manager the snapshot was taken from
+func (snap *Snapshot) IsSpinning(ss SpinStats) bool {
+	return ss.IsSpinning(snap.spinRate, snap.spinRatio)
+}
+
+// spinStats counts the default hits and the hits of the other top-level cases
+func spinStats(selectStats map[string]*SelectStats, lifetime time.Duration) SpinStats {
+	var ss SpinStats
+	for _, stats := range selectStats {
+		if stats.Kind == KindDefault {
+			ss.DefaultHits += stats.CaseHits
+			continue
+		}
+		if _, hasParent := selectStats[stats.Parent]; stats.Parent != "" && hasParent {
+			continue
+		}
+		ss.CaseHits += stats.CaseHits
+	}
+
+	if lifetime > 0 {
+		ss.Rate = float64(ss.DefaultHits) / lifetime.Seconds()
+	}
+	ss.Ratio = float64(ss.DefaultHits)
+	if ss.CaseHits > 0 {
+		ss.Ratio /= float64(ss.CaseHits)
+	}
+	return ss
+}
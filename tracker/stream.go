@@ -0,0 +1,126 @@
+//go:build !idlespy_off
+
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// snapshotStream periodically appends snapshots to an NDJSON file
+type snapshotStream struct {
+	file *os.File
+	stop chan struct{}
+	done chan struct{}
+}
+
+// StartStream appends a snapshot line to the file at path every interval until
+// StopStream or Done is called. Every line is synced to disk once written, so
+// a crash loses at most one interval. Earlier runs are kept in the file, a
+// run-start line separates them.
+func (gm *GoroutineManager) StartStream(path string, interval time.Duration) error {
+	if !gm.Enabled {
+		return nil
+	}
+
+	if interval <= 0 {
+		return fmt.Errorf("invalid stream interval: %v", interval)
+	}
+
+	// the file is opened and written outside gm.mu, tracking never waits on it
+	gm.streamMu.Lock()
+	defer gm.streamMu.Unlock()
+
+	if gm.stream != nil {
+		return errors.New("stream already started")
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening stream file: %w", err)
+	}
+	if err := writeStreamLine(file, StreamRecord{Time: time.Now(), RunStart: true}); err != nil {
+		file.Close()
+		return err
+	}
+
+	gm.stream = &snapshotStream{
+		file: file,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go gm.runStream(gm.stream, interval)
+
+	return nil
+}
+
+// StopStream writes a final snapshot line and closes the stream file
+func (gm *GoroutineManager) StopStream() error {
+	gm.streamMu.Lock()
+	stream := gm.stream
+	gm.stream = nil
+	gm.streamMu.Unlock()
+
+	if stream == nil {
+		return nil
+	}
+
+	close(stream.stop)
+	<-stream.done
+
+	err := gm.writeStreamRecord(stream.file)
+	if closeErr := stream.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (gm *GoroutineManager) runStream(stream *snapshotStream, interval time.Duration) {
+	defer close(stream.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := gm.writeStreamRecord(stream.file); err != nil {
+				log.Printf("Error writing stream record: %v", err)
+			}
+		case <-stream.stop:
+			return
+		}
+	}
+}
+
+// writeStreamRecord appends the current snapshot as one NDJSON line
+func (gm *GoroutineManager) writeStreamRecord(file *os.File) error {
+	snap := gm.Snapshot()
+	return writeStreamLine(file, StreamRecord{
+		Time:      snap.Taken,
+		JSONStats: snap.JSON("stream"),
+	})
+}
+
+// writeStreamLine appends record as one NDJSON line and syncs it to disk.
+// A run-start line begins with a newline, the previous run may have ended
+// with a line cut short by a crash.
+func writeStreamLine(file *os.File, record StreamRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error marshaling stream record: %w", err)
+	}
+	if record.RunStart {
+		line = append([]byte{'\n'}, line...)
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing stream record: %w", err)
+	}
+
+	return file.Sync()
+}
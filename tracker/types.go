@@ -1,8 +1,13 @@
 package tracker
 
 import (
+	"container/list"
+	"hash/maphash"
+	"math/rand/v2"
+	"net/http"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -17,38 +22,644 @@ const (
 
 // GoroutineManager manages statistics for multiple goroutines
 type GoroutineId int
+
+// UnknownParent is the ParentId of a goroutine whose creator could not be read
+// because its stack was too deep when tracking started
+const UnknownParent GoroutineId = -1
+
 type GoroutineManager struct {
 	Stats    map[GoroutineId]*GoroutineStats
 	mu       *sync.RWMutex
 	Wg       *sync.WaitGroup
 	FileType string // text or json
 	Action   Action
+	// when false every tracking method is a no-op, set from IDLESPY_ENABLED
+	Enabled bool
+	// directory the reports are written to, empty means the working directory
+	OutputDir string
+	// sliding windows kept for every select case, nil disables them
+	Windows []time.Duration
+	// default sampling applied to every select case, zero records every hit
+	Sampling Sampling
+	// when true the time spent inside the tracking methods is reported
+	MeasureOverhead bool
+	// latencies kept per case for percentiles, older ones are replaced by
+	// reservoir sampling once full. Zero keeps every latency.
+	MaxLatencySamples int
+	// when true, hits of cases that were not registered with RegisterCase are
+	// dropped instead of only warned about
+	StrictCases bool
+	// distinct case names recorded across all goroutines, zero means unlimited.
+	// Hits of further names are folded into OtherCaseName.
+	MaxCaseNames int
+	// distinct case names recorded per goroutine, zero means unlimited
+	MaxCaseNamesPerGoroutine int
+	// when true the file, line and function that recorded each select case
+	// are captured, cases with an empty name always capture them
+	CaptureCallSites bool
+	// default-branch hits per second of lifetime above which a goroutine is
+	// reported as spinning, zero uses DefaultSpinRate
+	SpinRate float64
+	// default-branch hits per hit of the other cases above which a goroutine
+	// is reported as spinning, zero uses DefaultSpinRatio. Both must be exceeded.
+	SpinRatio float64
+	// finished goroutines kept individually, the most blocked ones are kept as
+	// exemplars and the others are rolled up into their group. Zero keeps all.
+	MaxFinishedGoroutines int
+
+	// start of the interval covered by the next snapshot
+	intervalStart time.Time
+	// periodic snapshot writer, nil unless StartStream was called
+	stream *snapshotStream
+	// guards stream, kept apart from mu so tracking does not wait on file I/O
+	streamMu *sync.Mutex
+	// live snapshot server, nil unless ListenAndServe was called
+	server *http.Server
+	// serializes report writes triggered by Done, signals and panics
+	flushMu *sync.Mutex
+	// installed signal handler, nil unless HandleSignals was called
+	signals *signalHandler
+	// samplers of the case names, created on their first hit or by
+	// RegisterCase and SetCaseSampling. Copied on write so TrackSelectCase can
+	// read them without locking.
+	samplers atomic.Pointer[map[string]*sampler]
+	// names of the registered cases, indexed by their handle. Copied on
+	// write so TrackCase resolves handles without locking.
+	caseNames   atomic.Pointer[[]string]
+	caseHandles map[string]CaseHandle
+	// unregistered case names already warned about
+	warnedCases map[string]struct{}
+	// case names admitted under MaxCaseNames
+	caseNameSet map[string]struct{}
+	// hashes of the names folded into OtherCaseName
+	droppedNames map[uint64]struct{}
+	hashSeed     maphash.Seed
+	// finished goroutines still in Stats, least blocked first
+	exemplars exemplarHeap
+	// rolled up finished goroutines, keyed by group
+	groups map[string]*GroupStats
+	// channels created with NewChan, keyed by name
+	channels map[string]*channelRecord
+	// periodic channel occupancy sampler, nil unless StartOccupancySampling was called
+	occupancy *occupancySampler
+	// locks recorded by Mutex and RWMutex, keyed by name
+	locks map[string]*lockRecord
+	// tracked goroutines that have not ended, channel and lock operations
+	// only look up the calling goroutine while some are running
+	running atomic.Int64
+	// kinds set with SetCaseKind, keyed by case name
+	caseKinds map[string]CaseKind
+	// contexts registered with WatchContext, keyed by goroutine
+	watches map[GoroutineId]contextWatch
+}
+
+// contextWatch is the function that stops watching a context registered with
+// WatchContext and when the context was cancelled, stored before gm.mu is
+// taken so the goroutine's end cannot overtake it
+type contextWatch struct {
+	stop        func() bool
+	cancelledAt *atomic.Pointer[time.Time]
+}
+
+// OtherCaseName is the case that collects hits of names over the cardinality limits
+const OtherCaseName = "__other__"
+
+// SiteCaseSeparator joins a select site and one of its cases into the name the
+// case is reported under, e.g. "worker.run/ctx_done"
+const SiteCaseSeparator = "/"
+
+// NestedCaseSeparator joins a parent case and a nested case into the name the
+// nested case is reported under, e.g. "item_received > result_sent"
+const NestedCaseSeparator = " > "
+
+// ChanSendSuffix and ChanRecvSuffix are appended to the name of a Chan to
+// form the select cases its send and receive waits are recorded under, e.g.
+// "jobs.send"
+const (
+	ChanSendSuffix = ".send"
+	ChanRecvSuffix = ".recv"
+)
+
+// LockCaseSuffix and RLockCaseSuffix are appended to the name of a Mutex or
+// RWMutex to form the select cases its acquisitions are recorded under, e.g.
+// "cache.lock"
+const (
+	LockCaseSuffix  = ".lock"
+	RLockCaseSuffix = ".rlock"
+)
+
+// WaitCaseSuffix is appended to the name of a WaitGroup or Cond and
+// AcquireCaseSuffix to the name of a Semaphore to form the select cases their
+// waits are recorded under, e.g. "workers.wait"
+const (
+	WaitCaseSuffix    = ".wait"
+	AcquireCaseSuffix = ".acquire"
+)
+
+// CaseKind is what a select case waits for, it decides which category the
+// case's blocked time is classified into
+type CaseKind string
+
+const (
+	// KindReceive waits for input, its blocked time is input-starved
+	KindReceive CaseKind = "receive"
+	// KindSend waits for room downstream, its blocked time is output-backpressured
+	KindSend CaseKind = "send"
+	// KindTimer waits for a timer or ticker, its blocked time is timer-idle
+	KindTimer CaseKind = "timer"
+	// KindContextDone waits for cancellation, its blocked time is cancellation
+	KindContextDone CaseKind = "context_done"
+	// KindDefault is the default branch of a select, it never blocks
+	KindDefault CaseKind = "default"
+)
+
+// DefaultCaseName is the case name whose kind is KindDefault unless set
+// otherwise with SetCaseKind
+const DefaultCaseName = "default"
+
+// Spin thresholds used when the manager's SpinRate and SpinRatio are zero
+const (
+	DefaultSpinRate  = 1000
+	DefaultSpinRatio = 10
+)
+
+// SpinStats measures how often a goroutine took the default branch of its
+// selects instead of blocking on one of their cases
+type SpinStats struct {
+	// hits of the KindDefault cases
+	DefaultHits int
+	// hits of the other top-level cases
+	CaseHits int
+	// default hits per second of lifetime
+	Rate float64
+	// default hits per hit of the other cases, DefaultHits when none was hit
+	Ratio float64
+}
+
+// BlockedCategories splits blocked time by what the goroutine was waiting for
+type BlockedCategories struct {
+	// waiting for input, on receive cases
+	InputStarved time.Duration
+	// waiting for room downstream, on send cases
+	OutputBackpressured time.Duration
+	// waiting for timers and tickers
+	TimerIdle time.Duration
+	// waiting for cancellation, on context-done cases
+	Cancellation time.Duration
+	// cases without a kind, locks and sync primitives
+	Unclassified time.Duration
+}
+
+// CaseHandle identifies a select case registered with RegisterCase
+type CaseHandle int
+
+// Sampling configures which hits of a select case are recorded. Recorded hits
+// are weighted so hit counts and blocked time totals stay unbiased, while
+// percentiles are computed from the recorded hits only. Every case name is
+// sampled on its own, across all goroutines.
+type Sampling struct {
+	// record one out of every Every hits
+	Every int
+	// record at most one hit per Interval, takes precedence over Every. Each
+	// recorded hit also stands for the hits skipped before it, so hits after
+	// the last recorded one are not counted.
+	Interval time.Duration
+}
+
+func (s Sampling) enabled() bool {
+	return s.Every > 1 || s.Interval > 0
+}
+
+// DefaultStreamPath is the file the CLI reads snapshot streams from
+const DefaultStreamPath = ".internal.ndjson"
+
+// StreamRecord is a single timestamped line of the snapshot stream
+type StreamRecord struct {
+	Time time.Time `json:"time"`
+	// set on the record StartStream writes before any snapshot, the totals
+	// of the records after it start from zero again
+	RunStart bool `json:"run_start,omitempty"`
+	JSONStats
+}
+
+// Snapshot is a point-in-time copy of a manager's statistics covering the
+// interval between Since and Taken
+type Snapshot struct {
+	Since time.Time
+	Taken time.Time
+	Stats map[GoroutineId]*GoroutineStats
+	// distinct case names folded into OtherCaseName, counting stops at 65536
+	DroppedCaseNames int
+	// finished goroutines rolled up by the retention policy, keyed by group
+	Groups map[string]*GroupStats
+	// channels created with NewChan, keyed by name
+	Channels map[string]*ChannelStats
+	// locks recorded by Mutex and RWMutex, keyed by name
+	Locks map[string]*LockStats
+	// spin thresholds of the manager when the snapshot was taken
+	spinRate  float64
+	spinRatio float64
 }
 
 // GoroutineStats holds statistics for a single goroutine
 type GoroutineStats struct {
 	GoroutineId GoroutineId
+	// group the goroutine is rolled up into once finished, see TrackGoroutineStartInGroup
+	Group string
+	// goroutine that created this one, zero for the main goroutine and
+	// UnknownParent when it could not be read. It may not be tracked.
+	ParentId GoroutineId
+	// go statement that created the goroutine, nil for the main goroutine
+	SpawnSite   *CallSite
 	SelectStats map[string]*SelectStats
 	StartTime   time.Time
 	EndTime     time.Time
+	// time spent inside the manager's tracking methods, only measured with MeasureOverhead
+	Overhead time.Duration
+	// work spans started with BeginSpan, keyed by name
+	Spans map[string]*SpanStats
+	// time covered by the goroutine's outermost spans
+	SpanTime time.Duration
+	// when the context registered with WatchContext was cancelled, zero if
+	// it was not
+	CancelledAt time.Time
+	// time between CancelledAt and TrackGoroutineEnd, zero until the
+	// cancelled goroutine ends
+	ShutdownLatency time.Duration
+	// how the goroutine ended, empty while it is running
+	ExitReason ExitReason
+	// the panic that ended the goroutine, nil unless ExitReason is ExitPanicked
+	Panic *Panic
+	// a receive on a closed Chan returned, see TrackGoroutineEnd
+	sawClosedChan bool
+	// select stats of the registered cases, indexed by their handle
+	cases []*SelectStats
+	// spans begun and not yet ended, innermost last
+	openSpans []openSpan
+	spanSeq   uint64
+	// select wait recorded while a span was open, it is not work
+	blockedInSpans time.Duration
+	// select handling recorded while no span was open, it is work
+	handlingOutsideSpans time.Duration
+}
+
+// SpanStats holds statistics for a named work span
+type SpanStats struct {
+	// how many times the span ended
+	Count int
+	// time between begin and end, including nested spans
+	TotalTime time.Duration
+	// time not covered by nested spans
+	SelfTime time.Duration
+}
+
+// Span is a work span started with BeginSpan, End it when the work is done
+type Span struct {
+	gm  *GoroutineManager
+	id  GoroutineId
+	seq uint64
+}
+
+// openSpan is a span that has begun and not ended yet
+type openSpan struct {
+	name     string
+	seq      uint64
+	start    time.Time
+	children time.Duration
+}
+
+// DefaultGroup is the group of goroutines started with TrackGoroutineStart
+const DefaultGroup = "default"
+
+// GroupStats aggregates the finished goroutines of a group that were evicted
+// from the manager's Stats by the retention policy
+type GroupStats struct {
+	Group string
+	// number of goroutines rolled up
+	Goroutines int
+	// sum of the goroutines' lifetimes
+	Lifetime    time.Duration
+	Overhead    time.Duration
+	SelectStats map[string]*SelectStats
+	// sum of the goroutines' working time, see GoroutineStats.GetWorkingTime
+	WorkingTime time.Duration
+	Spans       map[string]*SpanStats
+	// exits of the rolled up goroutines that were cancelled, nil if none was
+	Shutdown *ShutdownStats
+	// how the rolled up goroutines ended
+	Exits *ExitStats
+}
+
+// ExitReason is how a goroutine ended
+type ExitReason string
+
+const (
+	// ExitReturned is a goroutine whose function returned
+	ExitReturned ExitReason = "returned"
+	// ExitCancelled is a goroutine that ended after its context was cancelled
+	ExitCancelled ExitReason = "cancelled"
+	// ExitPanicked is a goroutine that panicked, see RecoverAndFlush
+	ExitPanicked ExitReason = "panicked"
+	// ExitChannelClosed is a goroutine that ended because a channel it
+	// received from was closed
+	ExitChannelClosed ExitReason = "channel_closed"
+)
+
+// maxPanics bounds the panics kept per ExitStats
+const maxPanics = 5
+
+// Panic is a recovered panic that ended a goroutine
+type Panic struct {
+	GoroutineId GoroutineId
+	// the value passed to panic, formatted with fmt.Sprint
+	Value string
+	// stack of the panicking goroutine
+	Stack string
+}
+
+// ExitStats counts how the goroutines of a group ended
+type ExitStats struct {
+	Group string
+	// goroutines that ended, keyed by how
+	Reasons map[ExitReason]int
+	// first panics that ended a goroutine of the group
+	Panics []Panic
+}
+
+// maxSlowestExits bounds the slowest exits kept per ShutdownStats
+const maxSlowestExits = 5
+
+// ShutdownStats holds how long the cancelled goroutines of a group took to
+// exit after their context was cancelled
+type ShutdownStats struct {
+	Group string
+	// cancelled goroutines that ended
+	Exits int
+	// cancelled goroutines still running when the stats were taken
+	Pending      int
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+	// slowest goroutines to exit, slowest first. Pending goroutines are
+	// listed with the time they have been running since the cancellation.
+	Slowest []SlowExit
+	// exit latencies for percentile calculations, bounded by reservoir sampling
+	latencies []time.Duration
+}
+
+// SlowExit is a goroutine that took long to exit after its cancellation
+type SlowExit struct {
+	GoroutineId GoroutineId
+	Latency     time.Duration
+	// true if the goroutine had not exited yet
+	Pending bool
+}
+
+// WaitGroup is a sync.WaitGroup that records how long each Wait blocked
+// under Name, on the tracked goroutine that waited. The zero value records
+// nothing until Manager is set.
+type WaitGroup struct {
+	Name    string
+	Manager *GoroutineManager
+
+	wg sync.WaitGroup
+}
+
+// Cond is a sync.Cond that records how long each Wait blocked, on the
+// tracked goroutine that waited. Create it with NewCond.
+type Cond struct {
+	// L is held while observing or changing the condition
+	L sync.Locker
+
+	gm   *GoroutineManager
+	name string
+	cond *sync.Cond
+}
+
+// Semaphore is a weighted semaphore that records how long each Acquire
+// blocked, on the tracked goroutine that waited. Create it with NewSemaphore.
+type Semaphore struct {
+	gm   *GoroutineManager
+	name string
+	size int64
+
+	mu  sync.Mutex
+	cur int64
+	// acquisitions waiting for room, in arrival order
+	waiters list.List
+}
+
+// Chan is a channel whose sends and receives are timed, create it with NewChan
+type Chan[T any] struct {
+	gm     *GoroutineManager
+	name   string
+	ch     chan T
+	record *channelRecord
+}
+
+// channelRecord guards the stats of the channels sharing a name, so sends and
+// receives do not take the manager's lock
+type channelRecord struct {
+	mu    sync.Mutex
+	stats ChannelStats
+}
+
+// ChannelStats holds statistics for a channel created with NewChan, over all
+// the goroutines that used it
+type ChannelStats struct {
+	Name     string
+	Capacity int
+	// completed sends and how long they waited for room in the channel
+	Sends        int
+	SendWaitTime time.Duration
+	// completed receives, including those that returned because the channel
+	// was closed, and how long they waited for a value
+	Receives     int
+	RecvWaitTime time.Duration
+	// buffer length observed after each send and receive
+	OccupancySamples int
+	TotalOccupancy   int
+	MaxOccupancy     int
+	// when the channel was closed and by which goroutine, zero while open
+	ClosedAt time.Time
+	ClosedBy GoroutineId
+	// buffer fill ratio sampled over time, nil unless StartOccupancySampling
+	// was called and the channel is buffered
+	Occupancy *OccupancyStats
+	// returns the number of values buffered in the channel
+	length func() int
+}
+
+// OccupancyStats holds the fill ratio, buffered values over capacity, of a
+// channel sampled periodically
+type OccupancyStats struct {
+	Samples   int
+	MinFill   float64
+	MaxFill   float64
+	TotalFill float64
+	// time the buffer was found full or empty, each sample stands for the
+	// time since the previous one
+	TimeAtFull  time.Duration
+	TimeAtEmpty time.Duration
+	// fill ratio over time. Once maxOccupancyPoints are kept adjacent points
+	// are averaged together, so the series stays bounded over long runs.
+	Series []OccupancyPoint
+	// samples averaged into each point, and those waiting for the next point
+	stride       int
+	pending      int
+	pendingFill  float64
+	pendingStart time.Time
+	last         time.Time
+}
+
+// OccupancyPoint is the average fill ratio of a channel from Time until the next point
+type OccupancyPoint struct {
+	Time time.Time `json:"time"`
+	Fill float64   `json:"fill"`
+}
+
+// Mutex is a sync.Mutex that records how long each Lock waited and how long
+// the lock was then held under Name. The zero value is an unlocked mutex that
+// records nothing until Manager is set.
+type Mutex struct {
+	Name    string
+	Manager *GoroutineManager
+
+	mu sync.Mutex
+	// goroutine holding the lock, when it acquired it and how long it waited
+	hold lockHold
+	// stats of the lock, resolved on first use
+	record atomic.Pointer[lockRecord]
+}
+
+// RWMutex is a sync.RWMutex that records how long each Lock and RLock waited
+// and how long the lock was then held under Name. The zero value is an
+// unlocked mutex that records nothing until Manager is set.
+type RWMutex struct {
+	Name    string
+	Manager *GoroutineManager
+
+	mu   sync.RWMutex
+	hold lockHold
+	// read holds in acquisition order, each RUnlock releases the oldest
+	readersMu sync.Mutex
+	readers   []lockHold
+	record    atomic.Pointer[lockRecord]
+}
+
+// lockHold is an acquired lock that has not been released yet
+type lockHold struct {
+	// goroutine that took the lock, zero when it is not tracked
+	id       GoroutineId
+	acquired time.Time
+	wait     time.Duration
+}
+
+// lockRecord guards the stats of the locks sharing a name, so acquisitions do
+// not take the manager's lock
+type lockRecord struct {
+	mu    sync.Mutex
+	stats LockStats
+}
+
+// LockStats holds statistics for a lock, over all the goroutines that took it
+type LockStats struct {
+	Name string
+	// acquisitions in write and read mode
+	Locks  int
+	RLocks int
+	// time spent waiting to acquire the lock and holding it
+	WaitTime time.Duration
+	HoldTime time.Duration
+	MaxWait  time.Duration
+	MaxHold  time.Duration
+}
+
+// CallSite is the location of the code that recorded a select case
+type CallSite struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// SiteStats summarizes the cases of one select statement, i.e. which case won
+// each time the select ran. Cases that never won are not known.
+type SiteStats struct {
+	Site string
+	// Site as shown in reports, the file name and line for sites named
+	// after their call
+	Name string
+	// times the select ran, estimated when sampling
+	Hits int
+	// time the select was blocked, summed over its cases
+	BlockedTime time.Duration
+	// times each case won and the time it was blocked, keyed by case name
+	Wins        map[string]int
+	CaseBlocked map[string]time.Duration
 }
 
 // SelectStats holds statistics for a select case
 type SelectStats struct {
-	// how long the case was blocked
+	// name of the case this one is nested in, empty for top-level cases. The
+	// time of a nested case is part of its parent's time.
+	Parent string
+	// select statement the case belongs to, empty unless recorded with TrackSiteCase
+	Site string
+	// code that first recorded the case, only set when call sites are captured
+	CallSite *CallSite
+	// what the case waits for, empty until set with SetCaseKind
+	Kind CaseKind
+	// how long the case was blocked waiting to become ready
 	BlockedCaseTime time.Duration
-	// how many times the case was hit
+	// how long the case's handler ran after it was ready, only recorded by
+	// TrackSelectCaseHandled and TrackCaseHandled
+	HandlingTime time.Duration
+	// how many times the case was hit, estimated when sampling
 	CaseHits int
+	// how many hits were actually measured
+	SampledHits int
 	// individual latencies for percentile calculations
 	latencies []time.Duration
-	mu        sync.Mutex
+	// capacity of latencies, zero means unbounded
+	maxLatencies int
+	// recent measurements, only set when the manager has windows configured
+	window *rollingWindow
+	mu     sync.Mutex
+}
+
+// WindowStats holds the statistics of a select case over a sliding window
+type WindowStats struct {
+	Window       time.Duration
+	Hits         int
+	BlockedTime  time.Duration
+	Percentile50 time.Duration
+	Percentile99 time.Duration
 }
 
 // AddLatency adds a new latency measurement to the stats
 func (s *SelectStats) AddLatency(latency time.Duration) {
-	s.latencies = append(s.latencies, latency)
-	s.BlockedCaseTime += latency
-	s.CaseHits++
+	s.AddSampledLatency(latency, 1)
+}
+
+// AddSampledLatency adds a latency measurement that stands for weight hits
+func (s *SelectStats) AddSampledLatency(latency time.Duration, weight int) {
+	s.AddSampledHit(latency, 0, weight)
+}
+
+// AddSampledHit adds the wait and handling time of a hit that stands for weight hits
+func (s *SelectStats) AddSampledHit(latency, handling time.Duration, weight int) {
+	if s.maxLatencies == 0 || len(s.latencies) < s.maxLatencies {
+		s.latencies = append(s.latencies, latency)
+	} else if i := rand.IntN(s.SampledHits + 1); i < s.maxLatencies {
+		s.latencies[i] = latency
+	}
+	s.BlockedCaseTime += latency * time.Duration(weight)
+	s.HandlingTime += handling * time.Duration(weight)
+	s.CaseHits += weight
+	s.SampledHits++
+
+	if s.window != nil {
+		s.window.add(time.Now(), latency, weight)
+	}
 }
 
 // GetPercentile returns the nth percentile latency
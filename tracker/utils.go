@@ -1,7 +1,9 @@
 package tracker
 
 import (
+	"math"
 	"os"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strconv"
@@ -23,28 +25,31 @@ func getGoroutineID() GoroutineId {
 }
 
 func (gm *GoroutineManager) handleTextActions() {
-	allStats := gm.GetAllStats()
+	snap := gm.Snapshot()
+	visualization := filepath.Join(gm.OutputDir, ".visualization")
+	internal := filepath.Join(gm.OutputDir, ".internal")
 	switch gm.Action {
 	case PrintAndSave:
-		PrintAndSaveStatsText(allStats, ".visualization")
-		SaveStatsJSON(allStats, ".internal")
+		printAndSaveSnapshotText(snap, visualization)
+		saveSnapshotJSON(snap, internal)
 	case Save:
-		SaveStatsText(allStats, ".visualization")
-		SaveStatsJSON(allStats, ".internal")
+		saveSnapshotText(snap, visualization)
+		saveSnapshotJSON(snap, internal)
 	case Print:
-		PrintStatsText(allStats, ".visualization")
+		writeStatsText(os.Stdout, snap, visualization)
 	}
 }
 
 func (gm *GoroutineManager) handleJsonActions() {
-	allStats := gm.GetAllStats()
+	snap := gm.Snapshot()
+	internal := filepath.Join(gm.OutputDir, ".internal")
 	switch gm.Action {
 	case PrintAndSave:
-		PrintAndSaveStatsJSON(allStats, ".internal")
+		printAndSaveSnapshotJSON(snap, internal)
 	case Save:
-		SaveStatsJSON(allStats, ".internal")
+		saveSnapshotJSON(snap, internal)
 	case Print:
-		PrintStatsJSON(allStats, ".internal")
+		printSnapshotJSON(snap, internal)
 	}
 }
 
@@ -82,7 +87,12 @@ func AggregateCaseStats(caseStats []*sharedtypes.CaseJSON) map[string]*sharedtyp
 	for _, stat := range caseStats {
 		if existing, exists := aggregatedStats[stat.CaseName]; exists {
 			existing.Hits += stat.Hits
+			existing.SampledHits += sampledHits(stat)
 			existing.TotalBlockedTime += stat.TotalBlockedTime
+			existing.TotalHandlingTime += stat.TotalHandlingTime
+			if existing.CallSite == nil {
+				existing.CallSite = stat.CallSite
+			}
 			existing.AvgBlockedTime += stat.AvgBlockedTime
 
 			if stat.Percentile90 > existing.Percentile90 {
@@ -93,18 +103,49 @@ func AggregateCaseStats(caseStats []*sharedtypes.CaseJSON) map[string]*sharedtyp
 			}
 		} else {
 			aggregatedStats[stat.CaseName] = &sharedtypes.CaseJSON{
-				CaseName:         stat.CaseName,
-				Hits:             stat.Hits,
-				TotalBlockedTime: stat.TotalBlockedTime,
-				AvgBlockedTime:   stat.AvgBlockedTime,
-				Percentile90:     stat.Percentile90,
-				Percentile99:     stat.Percentile99,
+				CaseName:          stat.CaseName,
+				Parent:            stat.Parent,
+				CallSite:          stat.CallSite,
+				Hits:              stat.Hits,
+				TotalBlockedTime:  stat.TotalBlockedTime,
+				TotalHandlingTime: stat.TotalHandlingTime,
+				AvgBlockedTime:    stat.AvgBlockedTime,
+				Percentile90:      stat.Percentile90,
+				Percentile99:      stat.Percentile99,
+				SampledHits:       sampledHits(stat),
 			}
 		}
 	}
+
+	for _, stat := range aggregatedStats {
+		if stat.Hits > 0 && stat.SampledHits < stat.Hits {
+			stat.SampleRate = float64(stat.SampledHits) / float64(stat.Hits)
+		} else {
+			stat.SampledHits = 0
+			stat.SampleRate = 0
+		}
+	}
 	return aggregatedStats
 }
 
+// sampledHits returns the number of measured hits, which is every hit for
+// cases that were not sampled
+func sampledHits(stat *sharedtypes.CaseJSON) int64 {
+	if stat.SampleRate == 0 {
+		return stat.Hits
+	}
+	return stat.SampledHits
+}
+
+// SamplingError returns the relative error, at 95% confidence, of hit counts
+// and totals estimated from the given number of measured hits
+func SamplingError(sampledHits int64) float64 {
+	if sampledHits == 0 {
+		return 1
+	}
+	return 1.96 / math.Sqrt(float64(sampledHits))
+}
+
 // SortCaseStats sorts the aggregated statistics based on the visualization type
 func SortCaseStats(stats []*sharedtypes.CaseJSON, visType sharedtypes.VisualizationType) {
 	sort.Slice(stats, func(i, j int) bool {
@@ -125,25 +166,14 @@ func SortCaseStats(stats []*sharedtypes.CaseJSON, visType sharedtypes.Visualizat
 	})
 }
 
-// GetMaxValue returns the maximum value for the given visualization type
+// GetMaxValue returns the maximum value for the given visualization type across
+// all cases, whatever their order
 func GetMaxValue(stats []*sharedtypes.CaseJSON, visType sharedtypes.VisualizationType) float64 {
-	if len(stats) == 0 {
-		return 0
-	}
-	switch visType {
-	case sharedtypes.TotalBlockedTime:
-		return float64(stats[0].TotalBlockedTime)
-	case sharedtypes.AverageTime:
-		return float64(stats[0].AvgBlockedTime)
-	case sharedtypes.Percentile90:
-		return float64(stats[0].Percentile90)
-	case sharedtypes.Percentile99:
-		return float64(stats[0].Percentile99)
-	case sharedtypes.TotalHits:
-		return float64(stats[0].Hits)
-	default:
-		return 0
+	var maxValue float64
+	for _, stat := range stats {
+		maxValue = max(maxValue, GetValueForCase(stat, visType))
 	}
+	return maxValue
 }
 
 // GetValueForCase returns the value for a case based on the visualization type
@@ -0,0 +1,167 @@
+package tracker
+
+import (
+	"math/bits"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// number of time slots kept per case, the largest window is split evenly across them
+	windowSlots = 60
+	// number of power-of-two latency bins kept per slot
+	histogramBins = 48
+)
+
+// DefaultWindows are the window sizes commonly shown on dashboards
+var DefaultWindows = []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute}
+
+// rollingWindow keeps aggregated measurements for the most recent slots,
+// using a fixed amount of memory regardless of how many hits are recorded.
+// Slots are allocated on first use, so cases hit a few times stay small.
+type rollingWindow struct {
+	windows []time.Duration
+	width   time.Duration
+	// nil until the first hit, then windowSlots entries that stay nil until
+	// a hit falls into them
+	slots []*windowSlot
+	// fixed query time for copies taken by snapshots, zero means time.Now()
+	asOf time.Time
+}
+
+// windowSlot holds the measurements recorded during one slot
+type windowSlot struct {
+	// slot number since the unix epoch, used to detect stale slots
+	epoch   int64
+	hits    int
+	blocked time.Duration
+	bins    [histogramBins]uint32
+}
+
+func newRollingWindow(windows []time.Duration) *rollingWindow {
+	windows = slices.Clone(windows)
+	slices.Sort(windows)
+
+	width := windows[len(windows)-1] / windowSlots
+	if width <= 0 {
+		width = 1
+	}
+
+	return &rollingWindow{
+		windows: windows,
+		width:   width,
+	}
+}
+
+// add records a latency measured at now that stands for weight hits
+func (w *rollingWindow) add(now time.Time, latency time.Duration, weight int) {
+	epoch := now.UnixNano() / int64(w.width)
+	if w.slots == nil {
+		w.slots = make([]*windowSlot, windowSlots)
+	}
+	slot := w.slots[epoch%windowSlots]
+	if slot == nil {
+		slot = &windowSlot{epoch: epoch}
+		w.slots[epoch%windowSlots] = slot
+	} else if slot.epoch != epoch {
+		*slot = windowSlot{epoch: epoch}
+	}
+
+	slot.hits += weight
+	slot.blocked += latency * time.Duration(weight)
+	slot.bins[histogramBin(latency)] += uint32(weight)
+}
+
+// stats aggregates the slots that fall inside the given window
+func (w *rollingWindow) stats(window time.Duration) WindowStats {
+	now := w.asOf
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	n := int64((window + w.width - 1) / w.width)
+	if n > windowSlots {
+		n = windowSlots
+	}
+
+	result := WindowStats{Window: window}
+	if w.slots == nil {
+		return result
+	}
+
+	var bins [histogramBins]uint32
+	epoch := now.UnixNano() / int64(w.width)
+	for i := range n {
+		slot := w.slots[(epoch-i)%windowSlots]
+		if slot == nil || slot.epoch != epoch-i {
+			continue
+		}
+		result.Hits += slot.hits
+		result.BlockedTime += slot.blocked
+		for b, count := range slot.bins {
+			bins[b] += count
+		}
+	}
+
+	result.Percentile50 = binPercentile(&bins, result.Hits, 50)
+	result.Percentile99 = binPercentile(&bins, result.Hits, 99)
+	return result
+}
+
+// clone returns a deep copy of the window, frozen at asOf
+func (w *rollingWindow) clone(asOf time.Time) *rollingWindow {
+	c := *w
+	c.asOf = asOf
+	if w.slots != nil {
+		c.slots = make([]*windowSlot, windowSlots)
+		for i, slot := range w.slots {
+			if slot != nil {
+				s := *slot
+				c.slots[i] = &s
+			}
+		}
+	}
+	return &c
+}
+
+// histogramBin returns the power-of-two bin a latency falls into
+func histogramBin(latency time.Duration) int {
+	if latency <= 0 {
+		return 0
+	}
+	return min(bits.Len64(uint64(latency)), histogramBins-1)
+}
+
+// binPercentile returns the approximate nth percentile of the histogram,
+// reported as the midpoint of the bin it falls into
+func binPercentile(bins *[histogramBins]uint32, total int, n float64) time.Duration {
+	if total == 0 {
+		return 0
+	}
+
+	target := int(float64(total-1) * n / 100.0)
+	seen := 0
+	for b, count := range bins {
+		seen += int(count)
+		if seen > target {
+			if b < 2 {
+				return time.Duration(b)
+			}
+			return time.Duration(3) << (b - 2)
+		}
+	}
+	return 0
+}
+
+// formatWindow renders a window size the way dashboards usually label it (1m, 5m, 1h)
+func formatWindow(window time.Duration) string {
+	s := window.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -13,15 +14,42 @@ import (
 
 // JSONStats represents the complete statistics structure for JSON output
 type JSONStats struct {
-	Title      string                   `json:"title"`
-	Goroutines map[string]GoroutineJSON `json:"goroutines"`
+	Title                string                   `json:"title"`
+	Goroutines           map[string]GoroutineJSON `json:"goroutines"`
+	TotalTrackerOverhead int64                    `json:"total_tracker_overhead"`
+	Groups               map[string]GroupJSON     `json:"groups"`
+	Sites                map[string]SiteJSON      `json:"sites"`
+	Channels             map[string]ChannelJSON   `json:"channels"`
 }
 
 // GoroutineJSON represents a single goroutine's statistics in JSON format
 type GoroutineJSON struct {
+	Group                  string                          `json:"group"`
+	ParentId               int                             `json:"parent_id"`
+	SpawnSite              *sharedtypes.CallSite           `json:"spawn_site"`
 	Lifetime               int64                           `json:"lifetime"`
 	TotalSelectBlockedTime int64                           `json:"total_select_blocked_time"`
+	BlockedCategories      *CategoriesJSON                 `json:"blocked_categories"`
+	Spin                   *SpinJSON                       `json:"spin"`
+	TotalHandlingTime      int64                           `json:"total_handling_time"`
+	WorkingTime            *int64                          `json:"working_time"`
+	UnaccountedTime        int64                           `json:"unaccounted_time"`
 	SelectCaseStats        map[string]sharedtypes.CaseJSON `json:"select_case_statistics"`
+	TrackerOverhead        int64                           `json:"tracker_overhead"`
+}
+
+// GroupJSON represents the rolled up goroutines of a group in JSON format
+type GroupJSON struct {
+	Goroutines             int                             `json:"goroutines"`
+	TotalLifetime          int64                           `json:"total_lifetime"`
+	TotalSelectBlockedTime int64                           `json:"total_select_blocked_time"`
+	BlockedCategories      *CategoriesJSON                 `json:"blocked_categories"`
+	Spin                   *SpinJSON                       `json:"spin"`
+	TotalHandlingTime      int64                           `json:"total_handling_time"`
+	WorkingTime            *int64                          `json:"working_time"`
+	UnaccountedTime        int64                           `json:"unaccounted_time"`
+	SelectCaseStats        map[string]sharedtypes.CaseJSON `json:"select_case_statistics"`
+	TrackerOverhead        int64                           `json:"tracker_overhead"`
 }
 
 // CaseStats represents statistics for a single case
@@ -55,6 +83,8 @@ func GenerateBarChartFromJSON(data []byte, visType sharedtypes.VisualizationType
 	}
 
 	printBarChart(stats, visType, goroutineCount)
+	printOverheadWarning(data)
+	printSpinWarnings(data)
 	return nil
 }
 
@@ -72,7 +102,18 @@ func ParseJSONToStats(data []byte) ([]*sharedtypes.CaseJSON, int, error) {
 		}
 	}
 
-	return result, len(input.Goroutines), nil
+	goroutineCount := len(input.Goroutines)
+	for _, group := range input.Groups {
+		for caseName, stat := range group.SelectCaseStats {
+			stat.CaseName = caseName
+			// weigh the group's average like the goroutines it stands for
+			stat.AvgBlockedTime *= int64(group.Goroutines)
+			result = append(result, &stat)
+		}
+		goroutineCount += group.Goroutines
+	}
+
+	return result, goroutineCount, nil
 }
 
 func printBarChart(caseStats []*sharedtypes.CaseJSON, visType sharedtypes.VisualizationType, goroutineCount int) {
@@ -90,6 +131,7 @@ func printBarChart(caseStats []*sharedtypes.CaseJSON, visType sharedtypes.Visual
 		aggregatedSlice = append(aggregatedSlice, stat)
 	}
 	tracker.SortCaseStats(aggregatedSlice, visType)
+	aggregatedSlice = nestCases(aggregatedSlice)
 
 	maxValue := tracker.GetMaxValue(aggregatedSlice, visType)
 
@@ -99,7 +141,10 @@ func printBarChart(caseStats []*sharedtypes.CaseJSON, visType sharedtypes.Visual
 
 	for _, stat := range aggregatedSlice {
 		value := tracker.GetValueForCase(stat, visType)
-		barLength := int(value / maxValue * float64(barWidth))
+		var barLength int
+		if maxValue > 0 {
+			barLength = int(value / maxValue * float64(barWidth))
+		}
 		if barLength == 0 && value > 0 {
 			barLength = 1
 		}
@@ -111,8 +156,20 @@ func printBarChart(caseStats []*sharedtypes.CaseJSON, visType sharedtypes.Visual
 			valueStr = fmt.Sprintf("%d", int(value))
 		}
 
+		if stat.SampleRate > 0 {
+			valueStr += fmt.Sprintf(" (sampled %.2f%%, ±%.1f%%)", stat.SampleRate*100, tracker.SamplingError(stat.SampledHits)*100)
+		}
+		if stat.CallSite != nil {
+			valueStr += fmt.Sprintf("  %s:%d", filepath.Base(stat.CallSite.File), stat.CallSite.Line)
+		}
+
+		label := stat.CaseName
+		if depth := caseDepth(stat, aggregatedStats); depth > 0 {
+			label = strings.Repeat("  ", depth-1) + "└ " + strings.TrimPrefix(stat.CaseName, stat.Parent+tracker.NestedCaseSeparator)
+		}
+
 		fmt.Printf("%-20s %s %s\n",
-			stat.CaseName,
+			label,
 			strings.Repeat("█", barLength),
 			valueStr)
 		continue
@@ -120,6 +177,52 @@ func printBarChart(caseStats []*sharedtypes.CaseJSON, visType sharedtypes.Visual
 	}
 }
 
+// nestCases reorders sorted cases so each one is directly followed by the
+// cases nested in it, keeping the sort order among siblings
+func nestCases(sorted []*sharedtypes.CaseJSON) []*sharedtypes.CaseJSON {
+	present := make(map[string]bool, len(sorted))
+	for _, stat := range sorted {
+		present[stat.CaseName] = true
+	}
+
+	children := make(map[string][]*sharedtypes.CaseJSON)
+	var roots []*sharedtypes.CaseJSON
+	for _, stat := range sorted {
+		if stat.Parent != "" && present[stat.Parent] {
+			children[stat.Parent] = append(children[stat.Parent], stat)
+		} else {
+			roots = append(roots, stat)
+		}
+	}
+
+	result := make([]*sharedtypes.CaseJSON, 0, len(sorted))
+	var visit func(stat *sharedtypes.CaseJSON)
+	visit = func(stat *sharedtypes.CaseJSON) {
+		result = append(result, stat)
+		for _, child := range children[stat.CaseName] {
+			visit(child)
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+	return result
+}
+
+// caseDepth returns how deep a case is nested among the charted cases
+func caseDepth(stat *sharedtypes.CaseJSON, cases map[string]*sharedtypes.CaseJSON) int {
+	depth := 0
+	for stat.Parent != "" {
+		parent, ok := cases[stat.Parent]
+		if !ok {
+			break
+		}
+		depth++
+		stat = parent
+	}
+	return depth
+}
+
 func formatDuration(d time.Duration) string {
 	if d >= time.Second {
 		return fmt.Sprintf("~%.2fs", d.Seconds())
@@ -0,0 +1,170 @@
+package visualization
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CategoriesJSON represents blocked time by category in JSON format
+type CategoriesJSON struct {
+	InputStarved        int64 `json:"input_starved"`
+	OutputBackpressured int64 `json:"output_backpressured"`
+	TimerIdle           int64 `json:"timer_idle"`
+	Cancellation        int64 `json:"cancellation"`
+	Unclassified        int64 `json:"unclassified"`
+}
+
+// GroupCategories holds the blocked time by category of every goroutine of a group
+type GroupCategories struct {
+	Group               string
+	Goroutines          int
+	InputStarved        time.Duration
+	OutputBackpressured time.Duration
+	TimerIdle           time.Duration
+	Cancellation        time.Duration
+	Unclassified        time.Duration
+}
+
+// Total returns the blocked time of every category
+func (gc GroupCategories) Total() time.Duration {
+	return gc.InputStarved + gc.OutputBackpressured + gc.TimerIdle + gc.Cancellation + gc.Unclassified
+}
+
+func (gc *GroupCategories) add(c *CategoriesJSON) {
+	gc.InputStarved += time.Duration(c.InputStarved)
+	gc.OutputBackpressured += time.Duration(c.OutputBackpressured)
+	gc.TimerIdle += time.Duration(c.TimerIdle)
+	gc.Cancellation += time.Duration(c.Cancellation)
+	gc.Unclassified += time.Duration(c.Unclassified)
+}
+
+// categorySegments are the categories in the order they are drawn, with
+// the character of their bar segment
+var categorySegments = []struct {
+	name  string
+	char  string
+	value func(GroupCategories) time.Duration
+}{
+	{"input-starved", "█", func(gc GroupCategories) time.Duration { return gc.InputStarved }},
+	{"output-backpressured", "▓", func(gc GroupCategories) time.Duration { return gc.OutputBackpressured }},
+	{"timer-idle", "▒", func(gc GroupCategories) time.Duration { return gc.TimerIdle }},
+	{"cancellation", "░", func(gc GroupCategories) time.Duration { return gc.Cancellation }},
+	{"unclassified", "·", func(gc GroupCategories) time.Duration { return gc.Unclassified }},
+}
+
+// GenerateCategoryChart reads stats from a file and shows what the goroutines
+// of each group were blocked on
+func GenerateCategoryChart() error {
+	statsFile := ".internal.json"
+	data, err := os.ReadFile(statsFile)
+	if err != nil {
+		return fmt.Errorf("error reading stats file: %w", err)
+	}
+
+	err = GenerateCategoryChartFromJSON(data)
+	if err != nil {
+		return fmt.Errorf("error generating category chart: %w", err)
+	}
+
+	return nil
+}
+
+func GenerateCategoryChartFromJSON(data []byte) error {
+	groups, err := ParseJSONToCategories(data)
+	if err != nil {
+		return fmt.Errorf("error parsing stats: %w", err)
+	}
+
+	printCategoryChart(groups)
+	return nil
+}
+
+// ParseJSONToCategories sums the blocked time by category of the goroutines
+// kept individually and rolled up, per group sorted by name. Goroutines
+// without a group belong to the default group.
+func ParseJSONToCategories(data []byte) ([]GroupCategories, error) {
+	var input JSONStats
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, err
+	}
+
+	byGroup := make(map[string]*GroupCategories)
+	groupOf := func(name string) *GroupCategories {
+		if name == "" {
+			name = "default"
+		}
+		gc, exists := byGroup[name]
+		if !exists {
+			gc = &GroupCategories{Group: name}
+			byGroup[name] = gc
+		}
+		return gc
+	}
+
+	for _, g := range input.Goroutines {
+		if g.BlockedCategories == nil {
+			continue
+		}
+		gc := groupOf(g.Group)
+		gc.Goroutines++
+		gc.add(g.BlockedCategories)
+	}
+	for name, group := range input.Groups {
+		if group.BlockedCategories == nil {
+			continue
+		}
+		gc := groupOf(name)
+		gc.Goroutines += group.Goroutines
+		gc.add(group.BlockedCategories)
+	}
+
+	groups := make([]GroupCategories, 0, len(byGroup))
+	for _, gc := range byGroup {
+		groups = append(groups, *gc)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Group < groups[j].Group
+	})
+	return groups, nil
+}
+
+func printCategoryChart(groups []GroupCategories) {
+	if len(groups) == 0 {
+		fmt.Println("No blocked time categories found, the stats were written by an older tracker")
+		return
+	}
+
+	fmt.Println("\nBlocked Time By Category")
+	fmt.Println(strings.Repeat("=", 30))
+	legend := make([]string, len(categorySegments))
+	for i, segment := range categorySegments {
+		legend[i] = segment.char + " " + segment.name
+	}
+	fmt.Println(strings.Join(legend, "  "))
+
+	barWidth := 50
+	for _, gc := range groups {
+		total := gc.Total()
+		fmt.Printf("\n%s (%d goroutines, blocked %s)\n", gc.Group, gc.Goroutines, formatDuration(total))
+		if total <= 0 {
+			continue
+		}
+
+		var bar strings.Builder
+		for _, segment := range categorySegments {
+			width := int(float64(segment.value(gc)) / float64(total) * float64(barWidth))
+			bar.WriteString(strings.Repeat(segment.char, width))
+		}
+		fmt.Printf("  [%s]\n", bar.String())
+
+		for _, segment := range categorySegments {
+			if value := segment.value(gc); value > 0 {
+				fmt.Printf("  %-20s %5.1f%%  %s\n", segment.name, float64(value)/float64(total)*100, formatDuration(value))
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package visualization
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ExitJSON represents how the goroutines of a group ended in JSON format
+type ExitJSON struct {
+	Reasons map[string]int64 `json:"reasons"`
+	Panics  []PanicJSON      `json:"panics"`
+}
+
+// PanicJSON represents a panic that ended a goroutine in JSON format
+type PanicJSON struct {
+	GoroutineId int    `json:"goroutine_id"`
+	Value       string `json:"value"`
+	Stack       string `json:"stack"`
+}
+
+// GroupExits holds how the goroutines of a group ended
+type GroupExits struct {
+	Group   string
+	Total   int64
+	Reasons map[string]int64
+	Panics  []PanicJSON
+}
+
+// exitReasons are the reasons in the order they are listed, with the
+// character of their bar segment. Unknown reasons are listed after them.
+var exitReasons = []struct {
+	name string
+	char string
+}{
+	{"returned", "█"},
+	{"cancelled", "▓"},
+	{"channel_closed", "▒"},
+	{"panicked", "!"},
+}
+
+// GenerateExitChart reads stats from a file and shows how the goroutines of
+// each group ended
+func GenerateExitChart() error {
+	statsFile := ".internal.json"
+	data, err := os.ReadFile(statsFile)
+	if err != nil {
+		return fmt.Errorf("error reading stats file: %w", err)
+	}
+
+	err = GenerateExitChartFromJSON(data)
+	if err != nil {
+		return fmt.Errorf("error generating exit chart: %w", err)
+	}
+
+	return nil
+}
+
+func GenerateExitChartFromJSON(data []byte) error {
+	groups, err := ParseJSONToExits(data)
+	if err != nil {
+		return fmt.Errorf("error parsing stats: %w", err)
+	}
+
+	printExitChart(groups)
+	return nil
+}
+
+// ParseJSONToExits returns how the goroutines of every group ended, groups
+// with panics first and the others sorted by name
+func ParseJSONToExits(data []byte) ([]GroupExits, error) {
+	var input struct {
+		Exits map[string]ExitJSON `json:"exits"`
+	}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, err
+	}
+
+	groups := make([]GroupExits, 0, len(input.Exits))
+	for name, exits := range input.Exits {
+		g := GroupExits{Group: name, Reasons: exits.Reasons, Panics: exits.Panics}
+		for _, count := range exits.Reasons {
+			g.Total += count
+		}
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		pi, pj := groups[i].Reasons["panicked"], groups[j].Reasons["panicked"]
+		if pi != pj {
+			return pi > pj
+		}
+		return groups[i].Group < groups[j].Group
+	})
+	return groups, nil
+}
+
+func printExitChart(groups []GroupExits) {
+	if len(groups) == 0 {
+		fmt.Println("No exit reasons found, the stats were written by an older tracker")
+		return
+	}
+
+	fmt.Println("\nExit Reasons")
+	fmt.Println(strings.Repeat("=", 30))
+	legend := make([]string, len(exitReasons))
+	for i, reason := range exitReasons {
+		legend[i] = reason.char + " " + reason.name
+	}
+	fmt.Println(strings.Join(legend, "  "))
+
+	barWidth := 50
+	for _, g := range groups {
+		fmt.Printf("\n%s (%d goroutines ended)\n", g.Group, g.Total)
+		if g.Total == 0 {
+			continue
+		}
+
+		var bar strings.Builder
+		for _, reason := range exitReasons {
+			width := int(float64(g.Reasons[reason.name]) / float64(g.Total) * float64(barWidth))
+			bar.WriteString(strings.Repeat(reason.char, width))
+		}
+		fmt.Printf("  [%s]\n", bar.String())
+
+		names := make([]string, 0, len(g.Reasons))
+		for name := range g.Reasons {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return exitReasonOrder(names[i]) < exitReasonOrder(names[j]) ||
+				exitReasonOrder(names[i]) == exitReasonOrder(names[j]) && names[i] < names[j]
+		})
+		for _, name := range names {
+			count := g.Reasons[name]
+			fmt.Printf("  %-15s %5.1f%%  %d\n", name, float64(count)/float64(g.Total)*100, count)
+		}
+
+		if panicked := g.Reasons["panicked"]; panicked > 0 {
+			fmt.Printf("  Warning: %d goroutine(s) died from a panic\n", panicked)
+		}
+		for _, p := range g.Panics {
+			fmt.Printf("    goroutine %d: %s\n", p.GoroutineId, p.Value)
+		}
+	}
+}
+
+// exitReasonOrder returns where a reason is listed, unknown reasons last
+func exitReasonOrder(name string) int {
+	for i, reason := range exitReasons {
+		if reason.name == name {
+			return i
+		}
+	}
+	return len(exitReasons)
+}
@@ -1,5 +1,16 @@
 package visualization
 
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// OverheadThreshold is the fraction of the goroutines' lifetime the tracker may
+// spend recording before the charts print a warning
+var OverheadThreshold = 0.01
+
 func ConvertToSeconds(value float64, unit string) float64 {
 	switch unit {
 	case "s", "sec", "second", "seconds":
@@ -14,3 +25,95 @@ func ConvertToSeconds(value float64, unit string) float64 {
 		return value
 	}
 }
+
+// OverheadRatio returns the fraction of the goroutines' total lifetime the
+// tracker spent recording, rolled up groups included, and the goroutines above
+// OverheadThreshold. A group above it on average counts all of its goroutines.
+func OverheadRatio(input JSONStats) (float64, int) {
+	var lifetime, overhead int64
+	exceeding := 0
+	for _, g := range input.Goroutines {
+		lifetime += g.Lifetime
+		overhead += g.TrackerOverhead
+		if g.Lifetime > 0 && float64(g.TrackerOverhead)/float64(g.Lifetime) > OverheadThreshold {
+			exceeding++
+		}
+	}
+	for _, group := range input.Groups {
+		lifetime += group.TotalLifetime
+		overhead += group.TrackerOverhead
+		if group.TotalLifetime > 0 && float64(group.TrackerOverhead)/float64(group.TotalLifetime) > OverheadThreshold {
+			exceeding += group.Goroutines
+		}
+	}
+
+	if lifetime == 0 {
+		return 0, exceeding
+	}
+	return float64(overhead) / float64(lifetime), exceeding
+}
+
+// printOverheadWarning warns when the tracker's own overhead may distort the results
+func printOverheadWarning(data []byte) {
+	var input JSONStats
+	if err := json.Unmarshal(data, &input); err != nil {
+		return
+	}
+
+	ratio, exceeding := OverheadRatio(input)
+	if ratio <= OverheadThreshold && exceeding == 0 {
+		return
+	}
+
+	fmt.Printf("\nWarning: IdleSpy spent %s recording, %.2f%% of the total goroutine lifetime (threshold %.2f%%).\n",
+		formatDuration(time.Duration(input.TotalTrackerOverhead)), ratio*100, OverheadThreshold*100)
+	if exceeding > 0 {
+		fmt.Printf("         %d goroutine(s) exceed the threshold, consider sampling their select cases.\n", exceeding)
+	}
+}
+
+// SpinJSON holds how often the default branch of a select was taken
+type SpinJSON struct {
+	DefaultHits int64   `json:"default_hits"`
+	CaseHits    int64   `json:"case_hits"`
+	Rate        float64 `json:"rate"`
+	Ratio       float64 `json:"ratio"`
+	Spinning    bool    `json:"spinning"`
+}
+
+// SpinningGoroutines returns the goroutines and groups flagged as spinning on
+// a default branch, sorted by name
+func SpinningGoroutines(input JSONStats) []string {
+	var spinning []string
+	for id, g := range input.Goroutines {
+		if g.Spin != nil && g.Spin.Spinning {
+			spinning = append(spinning, "goroutine "+id)
+		}
+	}
+	for name, group := range input.Groups {
+		if group.Spin != nil && group.Spin.Spinning {
+			spinning = append(spinning, "group "+name)
+		}
+	}
+	slices.Sort(spinning)
+	return spinning
+}
+
+// printSpinWarnings warns about goroutines that busy-loop on a default branch
+// instead of blocking
+func printSpinWarnings(data []byte) {
+	var input JSONStats
+	if err := json.Unmarshal(data, &input); err != nil {
+		return
+	}
+
+	spinning := SpinningGoroutines(input)
+	if len(spinning) == 0 {
+		return
+	}
+
+	fmt.Printf("\nWarning: %d goroutine(s) or group(s) are spinning on a select default branch:\n", len(spinning))
+	for _, name := range spinning {
+		fmt.Printf("         %s\n", name)
+	}
+}
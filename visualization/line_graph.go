@@ -15,9 +15,17 @@ type GoroutineStats struct {
 	ID               int
 	Lifetime         time.Duration
 	TotalBlockedTime time.Duration
-	StartTime        time.Time
-	EndTime          time.Time
-	Efficiency       float64
+	// time spent working in spans and select case handlers, zero when not recorded
+	BusyTime time.Duration
+	// time neither blocked nor working
+	UnaccountedTime time.Duration
+	StartTime       time.Time
+	EndTime         time.Time
+	Efficiency      float64
+	TrackerOverhead time.Duration
+	// set for the aggregate of a group's rolled up goroutines
+	Group      string
+	Goroutines int
 }
 
 // GenerateLineGraph reads stats from a file and generates a line graph visualization
@@ -43,6 +51,8 @@ func GenerateLineGraphFromJSON(data []byte) error {
 	}
 
 	printLineGraph(stats)
+	printOverheadWarning(data)
+	printSpinWarnings(data)
 	return nil
 }
 
@@ -61,36 +71,89 @@ func ParseJSONToGoroutineStats(data []byte) ([]GoroutineStats, error) {
 		}
 
 		totalBlocked := time.Duration(g.TotalSelectBlockedTime)
+		busy, measured := busyTime(g.WorkingTime, g.TotalHandlingTime)
 		lifetime := time.Duration(g.Lifetime)
 
-		var efficiency float64
-		if lifetime > 0 {
-			efficiency = 1 - float64(totalBlocked)/float64(lifetime)
-		}
-
 		stats = append(stats, GoroutineStats{
 			ID:               id,
 			Lifetime:         lifetime,
-			Efficiency:       efficiency,
+			Efficiency:       efficiencyScore(lifetime, totalBlocked, busy, measured),
 			TotalBlockedTime: totalBlocked,
+			BusyTime:         busy,
+			UnaccountedTime:  max(lifetime-totalBlocked-busy, 0),
+			TrackerOverhead:  time.Duration(g.TrackerOverhead),
+		})
+	}
+
+	for name, group := range input.Groups {
+		totalBlocked := time.Duration(group.TotalSelectBlockedTime)
+		busy, measured := busyTime(group.WorkingTime, group.TotalHandlingTime)
+		lifetime := time.Duration(group.TotalLifetime)
+
+		stats = append(stats, GoroutineStats{
+			Lifetime:         lifetime,
+			Efficiency:       efficiencyScore(lifetime, totalBlocked, busy, measured),
+			TotalBlockedTime: totalBlocked,
+			BusyTime:         busy,
+			UnaccountedTime:  max(lifetime-totalBlocked-busy, 0),
+			TrackerOverhead:  time.Duration(group.TrackerOverhead),
+			Group:            name,
+			Goroutines:       group.Goroutines,
 		})
 	}
 
 	return stats, nil
 }
 
+// busyTime returns the working time reported by the tracker, falling back to
+// the select handling time for reports written before work spans existed. It
+// also reports whether the report measured busy time at all.
+func busyTime(working *int64, handling int64) (time.Duration, bool) {
+	if working != nil {
+		return time.Duration(*working), true
+	}
+	return time.Duration(handling), handling > 0
+}
+
+// workTime returns the busy time when it was measured, otherwise everything
+// that was not blocked is assumed to be work
+func workTime(lifetime, blocked, busy time.Duration, measured bool) time.Duration {
+	if measured {
+		return busy
+	}
+	return max(lifetime-blocked, 0)
+}
+
+// efficiencyScore returns the fraction of the lifetime spent working, see
+// workTime
+func efficiencyScore(lifetime, blocked, busy time.Duration, measured bool) float64 {
+	if lifetime <= 0 {
+		return 0
+	}
+	return float64(workTime(lifetime, blocked, busy, measured)) / float64(lifetime)
+}
+
 func printLineGraph(stats []GoroutineStats) {
 	if len(stats) == 0 {
 		fmt.Println("No valid goroutine statistics found")
 		return
 	}
 
+	// goroutines first, then the rolled up groups by name
 	sort.Slice(stats, func(i, j int) bool {
+		if (stats[i].Group == "") != (stats[j].Group == "") {
+			return stats[i].Group == ""
+		}
+		if stats[i].Group != stats[j].Group {
+			return stats[i].Group < stats[j].Group
+		}
 		return stats[i].ID < stats[j].ID
 	})
 
 	fmt.Println("\nGoroutine Efficiency Scores")
 	fmt.Println(strings.Repeat("=", 30))
+	fmt.Println("█ working  ▓ blocked  ░ unaccounted")
+	fmt.Println()
 
 	for _, g := range stats {
 		efficiency := g.Efficiency
@@ -100,19 +163,38 @@ func printLineGraph(stats []GoroutineStats) {
 			efficiency = 1
 		}
 
-		efficiencyPercent := efficiency * 100
-		barWidth := 40
-		filledWidth := int(efficiency * float64(barWidth))
-
-		fmt.Printf("Goroutine %-4d [%s%s] %.1f%%\n",
-			g.ID,
-			strings.Repeat("█", filledWidth),
-			strings.Repeat("░", barWidth-filledWidth),
-			efficiencyPercent)
+		label := fmt.Sprintf("Goroutine %-4d", g.ID)
+		if g.Group != "" {
+			label = fmt.Sprintf("Group %s (%d goroutines)", g.Group, g.Goroutines)
+		}
+		fmt.Printf("%s [%s] %.1f%%\n", label, stackedBar(g, 40), efficiency*100)
 
 		fmt.Printf("    Lifetime: %.6fs\n", g.Lifetime.Seconds())
 		fmt.Printf("    Blocked: %.6fs\n", g.TotalBlockedTime.Seconds())
+		fmt.Printf("    Working: %.6fs\n", g.BusyTime.Seconds())
+		fmt.Printf("    Unaccounted: %.6fs\n", g.UnaccountedTime.Seconds())
+		if g.TrackerOverhead > 0 {
+			fmt.Printf("    Tracker Overhead: %.6fs\n", g.TrackerOverhead.Seconds())
+		}
 		fmt.Println()
 	}
 
 }
+
+// stackedBar renders the goroutine's lifetime as working, blocked and
+// unaccounted segments
+func stackedBar(g GoroutineStats, width int) string {
+	if g.Lifetime <= 0 {
+		return strings.Repeat("░", width)
+	}
+
+	segment := func(d time.Duration) int {
+		return int(float64(d) / float64(g.Lifetime) * float64(width))
+	}
+	working := min(segment(g.BusyTime), width)
+	blocked := min(segment(g.TotalBlockedTime), width-working)
+
+	return strings.Repeat("█", working) +
+		strings.Repeat("▓", blocked) +
+		strings.Repeat("░", width-working-blocked)
+}
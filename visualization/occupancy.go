@@ -0,0 +1,155 @@
+package visualization
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChannelJSON represents the statistics of a tracked channel in JSON format
+type ChannelJSON struct {
+	Capacity  int            `json:"capacity"`
+	Occupancy *OccupancyJSON `json:"occupancy"`
+}
+
+// OccupancyJSON represents the sampled fill ratio of a channel in JSON format
+type OccupancyJSON struct {
+	Samples     int64                `json:"samples"`
+	MinFill     float64              `json:"min_fill"`
+	MeanFill    float64              `json:"mean_fill"`
+	MaxFill     float64              `json:"max_fill"`
+	TimeAtFull  int64                `json:"time_at_full"`
+	TimeAtEmpty int64                `json:"time_at_empty"`
+	Series      []OccupancyPointJSON `json:"series"`
+}
+
+// OccupancyPointJSON is the average fill ratio of a channel from Time until the next point
+type OccupancyPointJSON struct {
+	Time time.Time `json:"time"`
+	Fill float64   `json:"fill"`
+}
+
+// ChannelOccupancy holds the sampled fill ratio of one channel
+type ChannelOccupancy struct {
+	Name        string
+	Capacity    int
+	Samples     int64
+	MinFill     float64
+	MeanFill    float64
+	MaxFill     float64
+	TimeAtFull  time.Duration
+	TimeAtEmpty time.Duration
+	Series      []OccupancyPointJSON
+}
+
+// sparkLevels render a fill ratio from empty to full
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// GenerateOccupancyChart reads stats from a file and shows how full every
+// sampled channel was over the run
+func GenerateOccupancyChart() error {
+	statsFile := ".internal.json"
+	data, err := os.ReadFile(statsFile)
+	if err != nil {
+		return fmt.Errorf("error reading stats file: %w", err)
+	}
+
+	err = GenerateOccupancyChartFromJSON(data)
+	if err != nil {
+		return fmt.Errorf("error generating occupancy chart: %w", err)
+	}
+
+	return nil
+}
+
+func GenerateOccupancyChartFromJSON(data []byte) error {
+	channels, err := ParseJSONToOccupancy(data)
+	if err != nil {
+		return fmt.Errorf("error parsing stats: %w", err)
+	}
+
+	printOccupancyChart(channels)
+	return nil
+}
+
+// ParseJSONToOccupancy returns the channels with occupancy samples sorted by name
+func ParseJSONToOccupancy(data []byte) ([]ChannelOccupancy, error) {
+	var input JSONStats
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, err
+	}
+
+	var channels []ChannelOccupancy
+	for name, c := range input.Channels {
+		if c.Occupancy == nil {
+			continue
+		}
+		channels = append(channels, ChannelOccupancy{
+			Name:        name,
+			Capacity:    c.Capacity,
+			Samples:     c.Occupancy.Samples,
+			MinFill:     c.Occupancy.MinFill,
+			MeanFill:    c.Occupancy.MeanFill,
+			MaxFill:     c.Occupancy.MaxFill,
+			TimeAtFull:  time.Duration(c.Occupancy.TimeAtFull),
+			TimeAtEmpty: time.Duration(c.Occupancy.TimeAtEmpty),
+			Series:      c.Occupancy.Series,
+		})
+	}
+
+	sort.Slice(channels, func(i, j int) bool {
+		return channels[i].Name < channels[j].Name
+	})
+	return channels, nil
+}
+
+// Sparkline renders the series in at most width columns, averaging the
+// points that fall into the same column
+func (c ChannelOccupancy) Sparkline(width int) string {
+	if len(c.Series) == 0 || width <= 0 {
+		return ""
+	}
+
+	columns := min(width, len(c.Series))
+	var b strings.Builder
+	for col := range columns {
+		from := col * len(c.Series) / columns
+		to := (col + 1) * len(c.Series) / columns
+		var fill float64
+		for _, p := range c.Series[from:to] {
+			fill += p.Fill
+		}
+		fill /= float64(to - from)
+
+		level := int(min(max(fill, 0), 1) * float64(len(sparkLevels)-1))
+		b.WriteRune(sparkLevels[level])
+	}
+	return b.String()
+}
+
+func printOccupancyChart(channels []ChannelOccupancy) {
+	if len(channels) == 0 {
+		fmt.Println("No channel occupancy found, create channels with NewChan and call StartOccupancySampling")
+		return
+	}
+
+	fmt.Println("\nChannel Occupancy")
+	fmt.Println(strings.Repeat("=", 30))
+
+	width := 60
+	for _, c := range channels {
+		fmt.Printf("\n%s (capacity %d, %d samples)\n", c.Name, c.Capacity, c.Samples)
+		fmt.Printf("  fill min %.1f%%, mean %.1f%%, max %.1f%%, full %s, empty %s\n",
+			c.MinFill*100, c.MeanFill*100, c.MaxFill*100,
+			formatDuration(c.TimeAtFull), formatDuration(c.TimeAtEmpty))
+		if len(c.Series) == 0 {
+			continue
+		}
+
+		span := c.Series[len(c.Series)-1].Time.Sub(c.Series[0].Time).Truncate(time.Millisecond)
+		fmt.Printf("  [%s] over %s\n", c.Sparkline(width), span)
+	}
+}
@@ -0,0 +1,149 @@
+package visualization
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ShutdownJSON represents how long the cancelled goroutines of a group took
+// to exit in JSON format
+type ShutdownJSON struct {
+	Exits          int64          `json:"exits"`
+	Pending        int64          `json:"pending"`
+	AverageLatency int64          `json:"average_latency"`
+	Percentile50   int64          `json:"percentile_50"`
+	Percentile90   int64          `json:"percentile_90"`
+	Percentile99   int64          `json:"percentile_99"`
+	MaxLatency     int64          `json:"max_latency"`
+	Slowest        []SlowExitJSON `json:"slowest"`
+}
+
+// SlowExitJSON represents one of the slowest goroutines to exit in JSON format
+type SlowExitJSON struct {
+	GoroutineId int   `json:"goroutine_id"`
+	Latency     int64 `json:"latency"`
+	Pending     bool  `json:"pending"`
+}
+
+// GroupShutdown holds how long the cancelled goroutines of a group took to exit
+type GroupShutdown struct {
+	Group          string
+	Exits          int64
+	Pending        int64
+	AverageLatency time.Duration
+	Percentile50   time.Duration
+	Percentile90   time.Duration
+	Percentile99   time.Duration
+	MaxLatency     time.Duration
+	Slowest        []SlowExitJSON
+}
+
+// GenerateShutdownChart reads stats from a file and shows how long each
+// group took to exit after its context was cancelled
+func GenerateShutdownChart() error {
+	statsFile := ".internal.json"
+	data, err := os.ReadFile(statsFile)
+	if err != nil {
+		return fmt.Errorf("error reading stats file: %w", err)
+	}
+
+	err = GenerateShutdownChartFromJSON(data)
+	if err != nil {
+		return fmt.Errorf("error generating shutdown chart: %w", err)
+	}
+
+	return nil
+}
+
+func GenerateShutdownChartFromJSON(data []byte) error {
+	groups, err := ParseJSONToShutdowns(data)
+	if err != nil {
+		return fmt.Errorf("error parsing stats: %w", err)
+	}
+
+	printShutdownChart(groups)
+	return nil
+}
+
+// ParseJSONToShutdowns returns the shutdown latencies of every group, sorted
+// by their slowest exit
+func ParseJSONToShutdowns(data []byte) ([]GroupShutdown, error) {
+	var input struct {
+		Shutdowns map[string]ShutdownJSON `json:"shutdowns"`
+	}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, err
+	}
+
+	groups := make([]GroupShutdown, 0, len(input.Shutdowns))
+	for name, shutdown := range input.Shutdowns {
+		groups = append(groups, GroupShutdown{
+			Group:          name,
+			Exits:          shutdown.Exits,
+			Pending:        shutdown.Pending,
+			AverageLatency: time.Duration(shutdown.AverageLatency),
+			Percentile50:   time.Duration(shutdown.Percentile50),
+			Percentile90:   time.Duration(shutdown.Percentile90),
+			Percentile99:   time.Duration(shutdown.Percentile99),
+			MaxLatency:     time.Duration(shutdown.MaxLatency),
+			Slowest:        shutdown.Slowest,
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Pending != groups[j].Pending {
+			return groups[i].Pending > groups[j].Pending
+		}
+		if groups[i].MaxLatency != groups[j].MaxLatency {
+			return groups[i].MaxLatency > groups[j].MaxLatency
+		}
+		return groups[i].Group < groups[j].Group
+	})
+	return groups, nil
+}
+
+func printShutdownChart(groups []GroupShutdown) {
+	if len(groups) == 0 {
+		fmt.Println("No cancelled goroutines found, register their contexts with WatchContext")
+		return
+	}
+
+	fmt.Println("\nShutdown Latency")
+	fmt.Println(strings.Repeat("=", 30))
+
+	var longest time.Duration
+	for _, g := range groups {
+		longest = max(longest, g.MaxLatency)
+	}
+
+	barWidth := 40
+	for _, g := range groups {
+		fmt.Printf("\n%s (%d exited, %d still running)\n", g.Group, g.Exits, g.Pending)
+		if g.Exits > 0 {
+			for _, p := range []struct {
+				label string
+				value time.Duration
+			}{{"p50", g.Percentile50}, {"p90", g.Percentile90}, {"p99", g.Percentile99}, {"max", g.MaxLatency}} {
+				width := 0
+				if longest > 0 {
+					width = int(float64(p.value) / float64(longest) * float64(barWidth))
+				}
+				fmt.Printf("  %-4s %-*s %s\n", p.label, barWidth, strings.Repeat("█", width), formatDuration(p.value))
+			}
+		}
+
+		if len(g.Slowest) > 0 {
+			fmt.Println("  Slowest to exit:")
+		}
+		for _, exit := range g.Slowest {
+			if exit.Pending {
+				fmt.Printf("    goroutine %d  still running after %s\n", exit.GoroutineId, formatDuration(time.Duration(exit.Latency)))
+			} else {
+				fmt.Printf("    goroutine %d  %s\n", exit.GoroutineId, formatDuration(time.Duration(exit.Latency)))
+			}
+		}
+	}
+}
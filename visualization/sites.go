@@ -0,0 +1,144 @@
+package visualization
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SiteJSON represents the win distribution of a select site in JSON format
+type SiteJSON struct {
+	Name             string                  `json:"name"`
+	Runs             int64                   `json:"runs"`
+	TotalBlockedTime int64                   `json:"total_blocked_time"`
+	Fairness         float64                 `json:"fairness"`
+	Cases            map[string]SiteCaseJSON `json:"cases"`
+}
+
+// SiteCaseJSON represents one case of a select site in JSON format
+type SiteCaseJSON struct {
+	Wins             int64   `json:"wins"`
+	WinRate          float64 `json:"win_rate"`
+	TotalBlockedTime int64   `json:"total_blocked_time"`
+}
+
+// SiteStats holds the win distribution of one select site
+type SiteStats struct {
+	Site string
+	// Site as shown in the chart, see tracker.SiteStats
+	Name        string
+	Runs        int64
+	BlockedTime time.Duration
+	Fairness    float64
+	Cases       []SiteCaseStats
+}
+
+// SiteCaseStats holds how often one case of a select site won
+type SiteCaseStats struct {
+	CaseName    string
+	Wins        int64
+	WinRate     float64
+	BlockedTime time.Duration
+}
+
+// GenerateSiteChart reads stats from a file and shows the case win
+// distribution of every select site
+func GenerateSiteChart() error {
+	statsFile := ".internal.json"
+	data, err := os.ReadFile(statsFile)
+	if err != nil {
+		return fmt.Errorf("error reading stats file: %w", err)
+	}
+
+	err = GenerateSiteChartFromJSON(data)
+	if err != nil {
+		return fmt.Errorf("error generating site chart: %w", err)
+	}
+
+	return nil
+}
+
+func GenerateSiteChartFromJSON(data []byte) error {
+	sites, err := ParseJSONToSites(data)
+	if err != nil {
+		return fmt.Errorf("error parsing stats: %w", err)
+	}
+
+	printSiteChart(sites)
+	return nil
+}
+
+// ParseJSONToSites returns the select sites sorted by key, with their cases
+// sorted from most to least wins
+func ParseJSONToSites(data []byte) ([]SiteStats, error) {
+	var input JSONStats
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, err
+	}
+
+	var sites []SiteStats
+	for name, s := range input.Sites {
+		site := SiteStats{
+			Site:        name,
+			Name:        name,
+			Runs:        s.Runs,
+			BlockedTime: time.Duration(s.TotalBlockedTime),
+			Fairness:    s.Fairness,
+		}
+		if s.Name != "" {
+			site.Name = s.Name
+		}
+		for caseName, c := range s.Cases {
+			site.Cases = append(site.Cases, SiteCaseStats{
+				CaseName:    caseName,
+				Wins:        c.Wins,
+				WinRate:     c.WinRate,
+				BlockedTime: time.Duration(c.TotalBlockedTime),
+			})
+		}
+		sort.Slice(site.Cases, func(i, j int) bool {
+			if site.Cases[i].Wins != site.Cases[j].Wins {
+				return site.Cases[i].Wins > site.Cases[j].Wins
+			}
+			return site.Cases[i].CaseName < site.Cases[j].CaseName
+		})
+		sites = append(sites, site)
+	}
+
+	sort.Slice(sites, func(i, j int) bool {
+		return sites[i].Site < sites[j].Site
+	})
+	return sites, nil
+}
+
+func printSiteChart(sites []SiteStats) {
+	if len(sites) == 0 {
+		fmt.Println("No select sites found, record cases with TrackSiteCase")
+		return
+	}
+
+	fmt.Println("\nSelect Case Win Distribution")
+	fmt.Println(strings.Repeat("=", 30))
+
+	barWidth := 40
+	for _, site := range sites {
+		fmt.Printf("\n%s (%d runs, blocked %s, fairness %.2f)\n",
+			site.Name, site.Runs, formatDuration(site.BlockedTime), site.Fairness)
+
+		for _, c := range site.Cases {
+			barLength := int(c.WinRate * float64(barWidth))
+			if barLength == 0 && c.Wins > 0 {
+				barLength = 1
+			}
+			fmt.Printf("  %-20s %s %.1f%% (%d wins, blocked %s)\n",
+				c.CaseName,
+				strings.Repeat("█", barLength),
+				c.WinRate*100,
+				c.Wins,
+				formatDuration(c.BlockedTime))
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package visualization
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+// StreamRecord represents a single line of the snapshot stream
+type StreamRecord struct {
+	Time     time.Time `json:"time"`
+	RunStart bool      `json:"run_start"`
+	JSONStats
+}
+
+// TimelinePoint holds the totals of a stream record
+type TimelinePoint struct {
+	Time        time.Time
+	Hits        int64
+	BlockedTime time.Duration
+	Lifetime    time.Duration
+	// time spent working, see workTime
+	Busy         time.Duration
+	Efficiency   float64
+	HitsDelta    int64
+	BlockedDelta time.Duration
+	// first point of a run, its deltas start from zero
+	RunStart bool
+}
+
+// GenerateTimeline reads the snapshot stream and shows how the run evolved.
+// The stream is read from IDLESPY_OUTPUT_DIR when it is set, like the tracker
+// writes it.
+func GenerateTimeline() error {
+	data, err := os.ReadFile(tracker.StreamPath())
+	if err != nil {
+		return fmt.Errorf("error reading stream file: %w", err)
+	}
+
+	points, err := ParseStreamToTimeline(data)
+	if err != nil {
+		return fmt.Errorf("error parsing stream: %w", err)
+	}
+
+	printTimeline(points)
+	return nil
+}
+
+// ParseStreamToTimeline converts NDJSON snapshot lines into timeline points.
+// Lines that are not valid records, such as a line truncated by a crash, are
+// skipped. A run-start record resets the baseline the deltas are computed from.
+func ParseStreamToTimeline(data []byte) ([]TimelinePoint, error) {
+	var points []TimelinePoint
+	var prev *TimelinePoint
+	runStart := false
+
+	for line := range bytes.Lines(data) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		var record StreamRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			continue
+		}
+		if record.RunStart {
+			prev = nil
+			runStart = len(points) > 0
+			continue
+		}
+
+		point := TimelinePoint{Time: record.Time, RunStart: runStart}
+		runStart = false
+		for _, g := range record.Goroutines {
+			blocked := time.Duration(g.TotalSelectBlockedTime)
+			lifetime := time.Duration(g.Lifetime)
+			busy, measured := busyTime(g.WorkingTime, g.TotalHandlingTime)
+			point.BlockedTime += blocked
+			point.Lifetime += lifetime
+			point.Busy += workTime(lifetime, blocked, busy, measured)
+			for _, c := range g.SelectCaseStats {
+				point.Hits += c.Hits
+			}
+		}
+		for _, group := range record.Groups {
+			blocked := time.Duration(group.TotalSelectBlockedTime)
+			lifetime := time.Duration(group.TotalLifetime)
+			busy, measured := busyTime(group.WorkingTime, group.TotalHandlingTime)
+			point.BlockedTime += blocked
+			point.Lifetime += lifetime
+			point.Busy += workTime(lifetime, blocked, busy, measured)
+			for _, c := range group.SelectCaseStats {
+				point.Hits += c.Hits
+			}
+		}
+		point.Efficiency = efficiencyScore(point.Lifetime, point.BlockedTime, point.Busy, true)
+
+		point.HitsDelta = point.Hits
+		point.BlockedDelta = point.BlockedTime
+		if prev != nil {
+			point.HitsDelta -= prev.Hits
+			point.BlockedDelta -= prev.BlockedTime
+		}
+
+		points = append(points, point)
+		prev = &points[len(points)-1]
+	}
+
+	return points, nil
+}
+
+func printTimeline(points []TimelinePoint) {
+	if len(points) == 0 {
+		fmt.Println("No stream records found")
+		return
+	}
+
+	fmt.Println("\nRun Timeline")
+	fmt.Println(strings.Repeat("=", 30))
+
+	barWidth := 40
+	start := points[0].Time
+	for _, p := range points {
+		if p.RunStart {
+			fmt.Println(strings.Repeat("-", 12) + " new run " + strings.Repeat("-", 12))
+			start = p.Time
+		}
+		efficiency := min(max(p.Efficiency, 0), 1)
+		filledWidth := int(efficiency * float64(barWidth))
+
+		fmt.Printf("+%-10s [%s%s] %5.1f%%  hits +%-8d blocked +%s\n",
+			p.Time.Sub(start).Truncate(time.Millisecond),
+			strings.Repeat("█", filledWidth),
+			strings.Repeat("░", barWidth-filledWidth),
+			efficiency*100,
+			p.HitsDelta,
+			formatDuration(p.BlockedDelta))
+	}
+}
@@ -0,0 +1,168 @@
+package visualization
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlexsanderHamir/IdleSpy/sharedtypes"
+	"github.com/AlexsanderHamir/IdleSpy/tracker"
+)
+
+// TreeNode is a tracked goroutine in the spawn tree, with the totals of the
+// subtree it roots
+type TreeNode struct {
+	ID        int
+	ParentID  int
+	SpawnSite *sharedtypes.CallSite
+	Children  []*TreeNode
+
+	// totals of the goroutine and all of its descendants
+	Goroutines int
+	Lifetime   time.Duration
+	Blocked    time.Duration
+	// time spent working, or not blocked when the report did not measure
+	// it, see workTime
+	Busy time.Duration
+}
+
+// Efficiency returns the score of the subtree, computed like the score chart
+func (n *TreeNode) Efficiency() float64 {
+	return efficiencyScore(n.Lifetime, n.Blocked, n.Busy, true)
+}
+
+// GenerateTree reads stats from a file and renders the goroutine spawn tree
+func GenerateTree() error {
+	statsFile := ".internal.json"
+	data, err := os.ReadFile(statsFile)
+	if err != nil {
+		return fmt.Errorf("error reading stats file: %w", err)
+	}
+
+	err = GenerateTreeFromJSON(data)
+	if err != nil {
+		return fmt.Errorf("error generating tree: %w", err)
+	}
+
+	return nil
+}
+
+func GenerateTreeFromJSON(data []byte) error {
+	roots, err := ParseJSONToTree(data)
+	if err != nil {
+		return fmt.Errorf("error parsing stats: %w", err)
+	}
+
+	printTree(roots)
+	return nil
+}
+
+// ParseJSONToTree links the tracked goroutines to their parents and returns
+// the roots, i.e. the goroutines whose parent was not tracked or is unknown
+func ParseJSONToTree(data []byte) ([]*TreeNode, error) {
+	var input JSONStats
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[int]*TreeNode, len(input.Goroutines))
+	for idStr, g := range input.Goroutines {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid goroutine ID: %s", idStr)
+		}
+		lifetime := time.Duration(g.Lifetime)
+		blocked := time.Duration(g.TotalSelectBlockedTime)
+		busy, measured := busyTime(g.WorkingTime, g.TotalHandlingTime)
+		nodes[id] = &TreeNode{
+			ID:         id,
+			ParentID:   g.ParentId,
+			SpawnSite:  g.SpawnSite,
+			Goroutines: 1,
+			Lifetime:   lifetime,
+			Blocked:    blocked,
+			Busy:       workTime(lifetime, blocked, busy, measured),
+		}
+	}
+
+	var roots []*TreeNode
+	for _, node := range nodes {
+		if parent, ok := nodes[node.ParentID]; ok && node.ParentID != node.ID {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	sortNodes(roots)
+	for _, root := range roots {
+		aggregate(root)
+	}
+	return roots, nil
+}
+
+// aggregate adds the totals of every descendant to the node
+func aggregate(node *TreeNode) {
+	sortNodes(node.Children)
+	for _, child := range node.Children {
+		aggregate(child)
+		node.Goroutines += child.Goroutines
+		node.Lifetime += child.Lifetime
+		node.Blocked += child.Blocked
+		node.Busy += child.Busy
+	}
+}
+
+func sortNodes(nodes []*TreeNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+}
+
+func printTree(roots []*TreeNode) {
+	if len(roots) == 0 {
+		fmt.Println("No valid goroutine statistics found")
+		return
+	}
+
+	fmt.Println("\nGoroutine Spawn Tree")
+	fmt.Println(strings.Repeat("=", 30))
+	fmt.Println("Totals include every descendant goroutine")
+	fmt.Println()
+
+	for _, root := range roots {
+		printTreeNode(root, "", "")
+	}
+}
+
+func printTreeNode(node *TreeNode, prefix, childPrefix string) {
+	label := fmt.Sprintf("Goroutine %d", node.ID)
+	if node.SpawnSite != nil {
+		label += fmt.Sprintf(" (%s:%d)", filepath.Base(node.SpawnSite.File), node.SpawnSite.Line)
+	} else if node.ParentID == int(tracker.UnknownParent) {
+		label += " (parent unknown)"
+	}
+	if node.Goroutines > 1 {
+		label += fmt.Sprintf(" [%d goroutines]", node.Goroutines)
+	}
+
+	fmt.Printf("%s%s  lifetime %s, blocked %s, efficiency %.1f%%\n",
+		prefix,
+		label,
+		formatDuration(node.Lifetime),
+		formatDuration(node.Blocked),
+		min(max(node.Efficiency(), 0), 1)*100)
+
+	for i, child := range node.Children {
+		if i == len(node.Children)-1 {
+			printTreeNode(child, childPrefix+"└── ", childPrefix+"    ")
+		} else {
+			printTreeNode(child, childPrefix+"├── ", childPrefix+"│   ")
+		}
+	}
+}